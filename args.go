@@ -0,0 +1,71 @@
+package xsql
+
+import "sync"
+
+// maxPooledArgs is the capacity above which PutArgs drops a slice
+// rather than keeping it around. A full SaveAll batch of 65535 args takes
+// 1 MiB, too much for the pool to hold on to.
+const maxPooledArgs = 1024
+
+var (
+	argsPool = sync.Pool{
+		New: func() any {
+			s := make([]any, 0, 16)
+			return &s
+		},
+	}
+	// holderPool recycles the pointers argsPool stores slices in,
+	// so that putting a slice back doesn't allocate one.
+	holderPool = sync.Pool{
+		New: func() any {
+			return new([]any)
+		},
+	}
+)
+
+// GetArgs returns an empty argument slice with room for at least n args from a pool,
+// to save the allocation of an args slice per call in hot loops issuing the same
+// parameterized query over and over, e.g. through a StmtCache.
+// Hand it back with PutArgs once the query returned.
+//
+// SaveAll and InsertIgnore use it for the args of their batches.
+//
+// Example:
+//
+//	for _, id := range ids {
+//		args := GetArgs(1)
+//		args = append(args, id)
+//		user, err := QueryOne(ctx, stmts, scanUser, "SELECT id, name FROM users WHERE id = ?", args...)
+//		PutArgs(args)
+//		if err != nil {
+//			return err
+//		}
+//		process(user)
+//	}
+func GetArgs(n int) []any {
+	h := argsPool.Get().(*[]any)
+	s := *h
+	*h = nil
+	holderPool.Put(h)
+	if cap(s) < n {
+		return make([]any, 0, n)
+	}
+	return s[:0]
+}
+
+// PutArgs returns args, as obtained from GetArgs, to the pool. The args are
+// cleared so the pool doesn't keep their values alive. Slices with room for
+// more than 1024 args are not pooled, so a large batch doesn't pin its memory.
+//
+// args must not be used after PutArgs, not by the caller and not by anything
+// the slice was handed to, so only put it back once the query using it returned.
+// A query's rows don't keep their args, so they may still be read afterwards.
+func PutArgs(args []any) {
+	if cap(args) > maxPooledArgs {
+		return
+	}
+	clear(args[:cap(args)])
+	h := holderPool.Get().(*[]any)
+	*h = args[:0]
+	argsPool.Put(h)
+}
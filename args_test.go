@@ -0,0 +1,98 @@
+package xsql
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+var benchArgs []any
+
+// The benchmarks issue 1M calls building the args of a two placeholder query,
+// compare allocs/op:
+//
+//	go test -run '^$' -bench Args -benchtime 1000000x -benchmem
+func BenchmarkMakeArgs(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		args := make([]any, 0, 2)
+		args = append(args, 1, "a")
+		benchArgs = args
+	}
+}
+
+func BenchmarkGetArgs(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		args := GetArgs(2)
+		args = append(args, 1, "a")
+		benchArgs = args
+		benchArgs = nil
+		PutArgs(args)
+	}
+}
+
+func TestGetArgs(t *testing.T) {
+	args := GetArgs(3)
+	if len(args) != 0 || cap(args) < 3 {
+		t.Fatalf("GetArgs(3) = len %d cap %d, want len 0 cap >= 3", len(args), cap(args))
+	}
+	args = append(args, 1, 2, 3)
+	PutArgs(args)
+	if args[:3][0] != nil {
+		t.Errorf("PutArgs did not clear the args: %v", args[:3])
+	}
+
+	big := GetArgs(1 << 20)
+	if cap(big) < 1<<20 {
+		t.Errorf("GetArgs(1<<20) cap = %d", cap(big))
+	}
+	PutArgs(big)
+	if got := GetArgs(0); cap(got) > maxPooledArgs {
+		t.Errorf("GetArgs(0) cap = %d after PutArgs of a large slice, want at most %d", cap(got), maxPooledArgs)
+	}
+}
+
+func TestHookedArgsNoAlloc(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError}))
+	db := WithHooks(nopDB{}, Logger(logger))
+	args := []any{1, "a"}
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_, _ = db.ExecContext(ctx, "INSERT INTO t VALUES (?, ?)", args...)
+	})
+	if allocs != 0 {
+		t.Errorf("hooked exec with a disabled logger = %v allocs, want 0", allocs)
+	}
+}
+
+type sliceSink []AuditEntry
+
+func (s *sliceSink) Record(e AuditEntry) error {
+	*s = append(*s, e)
+	return nil
+}
+
+func TestInsertIgnoreAuditKeepsArgs(t *testing.T) {
+	db := openTestDB(t)
+	mustExec(t, db, "CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT)")
+
+	var sink sliceSink
+	type event struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	hooked := WithHooks(db, Audit(&sink, nil))
+	if _, _, err := InsertIgnore(context.Background(), hooked, Postgres, "events", []event{{1, "a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink) != 1 {
+		t.Fatalf("%d audit entries, want 1", len(sink))
+	}
+	if args := sink[0].Args; len(args) != 2 || args[0] != int64(1) || args[1] != "a" {
+		t.Errorf("audit args = %v, want [1 a]", args)
+	}
+}
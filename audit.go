@@ -0,0 +1,164 @@
+package xsql
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context recording actorID, e.g. the id of the authenticated
+// user, as the actor of the statements issued with it, for the Hook returned by Audit.
+func WithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+// ActorFrom returns the actor recorded in ctx by WithActor, or "" if there is none.
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// AuditEntry describes a mutating statement, as recorded by the Hook returned by Audit.
+type AuditEntry struct {
+	// Actor is the actor of the statement's context, see WithActor.
+	// It is empty for statements issued without one.
+	Actor string
+	Query string
+	Args  []any
+	// Time is when the statement was issued.
+	Time time.Time
+	// RowsAffected is the number of rows the statement changed,
+	// or -1 if it failed or the driver doesn't report it.
+	RowsAffected int64
+	// Err is the error the statement failed with.
+	Err error
+}
+
+// AuditSink stores audit entries, e.g. in an audit table or a log.
+type AuditSink interface {
+	Record(AuditEntry) error
+}
+
+// Audit returns a Hook recording every mutating statement run with ExecContext,
+// INSERT, UPDATE, DELETE, MERGE, REPLACE and TRUNCATE, to sink, failed ones included.
+// A leading WITH clause is looked past, and its common table expressions are
+// looked into, so WITH ... DELETE and Postgres data-modifying CTEs are recorded.
+// Statements issued with QueryContext, like INSERT ... RETURNING, are not recorded.
+//
+// Recording happens synchronously after the statement returned and never changes its
+// result. Errors of sink are logged at error level with logger, a nil logger means
+// slog.Default(), so a broken sink is noticed without failing the statements.
+//
+// Example:
+//
+//	db := WithHooks(sqlDB, Audit(auditTable, nil))
+//	ctx = WithActor(ctx, userID)
+//	_, err := db.ExecContext(ctx, "DELETE FROM documents WHERE id = ?", id)
+func Audit(sink AuditSink, logger *slog.Logger) Hook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return auditHook{sink: sink, logger: logger}
+}
+
+type auditHook struct {
+	sink   AuditSink
+	logger *slog.Logger
+}
+
+func (h auditHook) Before(context.Context, QueryEvent) {}
+
+func (h auditHook) After(ctx context.Context, e QueryEvent) {
+	if e.Op != "exec" || !isMutating(e.Query) {
+		return
+	}
+
+	entry := AuditEntry{
+		Actor:        ActorFrom(ctx),
+		Query:        e.Query,
+		Args:         slices.Clone(e.Args),
+		Time:         e.Start,
+		RowsAffected: -1,
+		Err:          e.Err,
+	}
+	if e.Result != nil {
+		if n, err := e.Result.RowsAffected(); err == nil {
+			entry.RowsAffected = n
+		}
+	}
+	if err := h.sink.Record(entry); err != nil {
+		h.logger.ErrorContext(ctx, "xsql: audit record failed",
+			slog.String("actor", entry.Actor),
+			slog.String("query", e.Query),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// isMutating reports whether query is a statement changing data.
+// A leading WITH clause is skipped, and a statement whose common table
+// expressions change data, as Postgres allows, is mutating too.
+func isMutating(query string) bool {
+	word, rest := firstWord(query)
+	switch word {
+	case "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "TRUNCATE":
+		return true
+	case "WITH":
+		return isMutatingWith(rest)
+	}
+	return false
+}
+
+// firstWord returns the first word of query, upper cased, and what follows it.
+func firstWord(query string) (word, rest string) {
+	q := strings.TrimLeft(query, " \t\r\n(")
+	i := strings.IndexFunc(q, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+	if i < 0 {
+		i = len(q)
+	}
+	return strings.ToUpper(q[:i]), q[i:]
+}
+
+// isMutatingWith reports whether the statement following WITH changes data,
+// in its common table expressions or in the main statement.
+func isMutatingWith(q string) bool {
+	depth := 0
+	// afterGroup is set at the end of a parenthesized group, a column list
+	// or a body, as the main statement follows the body of the last one.
+	afterGroup := false
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := strings.IndexByte(q[i+1:], c)
+			if j < 0 {
+				return false
+			}
+			i += j + 1
+		case c == '(':
+			if depth == 0 && isMutating(q[i+1:]) {
+				return true
+			}
+			depth++
+		case c == ')':
+			depth--
+			afterGroup = depth == 0
+		case depth > 0 || !afterGroup || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c == ',':
+			afterGroup = false
+		default:
+			word, _ := firstWord(q[i:])
+			if word != "AS" {
+				return isMutating(q[i:])
+			}
+			afterGroup = false
+			i += len(word) - 1
+		}
+	}
+	return false
+}
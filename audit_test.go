@@ -0,0 +1,27 @@
+package xsql
+
+import "testing"
+
+func TestIsMutating(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"INSERT INTO t VALUES (1)", true},
+		{"  (DELETE FROM t)", true},
+		{"update t SET a = 1", true},
+		{"SELECT * FROM t", false},
+		{"WITH old AS (SELECT id FROM t WHERE a < 1) DELETE FROM t WHERE id IN (SELECT id FROM old)", true},
+		{"WITH RECURSIVE r(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM r WHERE n < 3) INSERT INTO t SELECT n FROM r", true},
+		{"WITH a AS MATERIALIZED (SELECT 1), b AS (SELECT ')' FROM a) UPDATE t SET x = 1", true},
+		{"WITH a AS (SELECT 1), b(x) AS (SELECT 2) SELECT * FROM a, b", false},
+		{"WITH d AS (DELETE FROM t RETURNING id) SELECT count(*) FROM d", true},
+		{"WITH a AS (WITH b AS (SELECT 1) SELECT * FROM b) SELECT * FROM a", false},
+		{"WITH", false},
+	}
+	for _, tt := range tests {
+		if got := isMutating(tt.query); got != tt.want {
+			t.Errorf("isMutating(%q) = %t, want %t", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package xsql
+
+import (
+	"context"
+	"time"
+)
+
+// Budget splits the time budget of a request among its queries.
+// Each query gets a fraction of what is left, capped at a maximum, so one slow
+// query can not use up the whole budget and starve the ones after it.
+//
+// A Budget is meant for the sequential queries of one handler and is safe for concurrent use.
+//
+// Example:
+//
+//	b := NewBudget(500*time.Millisecond, 0.5, 200*time.Millisecond)
+//	user, err := QueryOneBudget(ctx, db, b, scanUser, "SELECT id, name FROM users WHERE id = ?", id)
+//	if err != nil {
+//		return err
+//	}
+//	if b.Remaining() < 50*time.Millisecond {
+//		return render(user, nil)
+//	}
+//	orders, err := QueryManyBudget(ctx, db, b, scanOrder, "SELECT id, total FROM orders WHERE user_id = ?", id)
+type Budget struct {
+	deadline time.Time
+	fraction float64
+	perQuery time.Duration
+}
+
+// NewBudget returns a Budget of total, starting now.
+// Each query gets fraction of the remaining budget, at most maxPerQuery.
+// A fraction outside (0, 1] gives the whole remaining budget, a maxPerQuery of 0 means no cap.
+func NewBudget(total time.Duration, fraction float64, maxPerQuery time.Duration) *Budget {
+	if fraction <= 0 || fraction > 1 {
+		fraction = 1
+	}
+	return &Budget{deadline: time.Now().Add(total), fraction: fraction, perQuery: maxPerQuery}
+}
+
+// Remaining returns the budget left, or 0 once it is spent,
+// so callers can decide whether the next query is still worth attempting.
+func (b *Budget) Remaining() time.Duration {
+	return max(time.Until(b.deadline), 0)
+}
+
+// Next returns the time the next query may take.
+// It can be passed to a statement timeout, e.g. postgres.WithStatementTimeout,
+// so the server gives up on the query as well.
+func (b *Budget) Next() time.Duration {
+	d := time.Duration(float64(b.Remaining()) * b.fraction)
+	if b.perQuery > 0 && d > b.perQuery {
+		d = b.perQuery
+	}
+	return d
+}
+
+// Context returns a child of ctx which expires after Next.
+// The deadline of ctx still applies when it comes first.
+func (b *Budget) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, b.Next())
+}
+
+// QueryOneBudget is like QueryOne, but runs the query with a slice of b.
+func QueryOneBudget[T any](
+	ctx context.Context,
+	db DBTX,
+	b *Budget,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) (T, error) {
+	ctx, cancel := b.Context(ctx)
+	defer cancel()
+	return QueryOne(ctx, db, scan, query, args...)
+}
+
+// QueryManyBudget is like QueryMany, but runs the query with a slice of b.
+// Scanning counts towards the slice, as rows are read within its context.
+func QueryManyBudget[T any](
+	ctx context.Context,
+	db DBTX,
+	b *Budget,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) ([]T, error) {
+	ctx, cancel := b.Context(ctx)
+	defer cancel()
+	return QueryMany(ctx, db, scan, query, args...)
+}
@@ -0,0 +1,133 @@
+package xsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Statement is a query along with its arguments, as assembled by a Builder.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Builder is implemented by the types assembling SQL for the helpers of this
+// package: Where for the clauses of Filter and NewWhere, UpsertBatch for SaveAll
+// and InsertIgnoreBatch for InsertIgnore. It lets the generated SQL be logged or
+// asserted on in unit tests without a database.
+type Builder interface {
+	// Statements returns the statements in the placeholder style of dialect.
+	Statements(dialect Dialect) ([]Statement, error)
+}
+
+// DryRun returns the statements b would run against a dialect database, or the
+// fragment it would produce for Where, without executing anything.
+//
+// Example:
+//
+//	stmts, err := DryRun(Postgres, UpsertBatch[Product]{Table: "products", ConflictCols: []string{"sku"}, Rows: products})
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, s := range stmts {
+//		log.Println(s.Query, s.Args)
+//	}
+func DryRun(dialect Dialect, b Builder) ([]Statement, error) {
+	return b.Statements(dialect)
+}
+
+// Rebind rewrites the ? placeholders of query in the placeholder style of dialect,
+// i.e. to $1, $2, ... for Postgres. Question marks inside quoted strings and
+// identifiers, -- and /* */ comments and dollar-quoted strings are left alone.
+// A query for MySQL is returned as is.
+//
+// For Postgres, operators spelled with a question mark, like the JSON ? operator,
+// are rewritten too, so use the equivalent function, e.g. jsonb_exists, instead.
+//
+// Example:
+//
+//	// SELECT id FROM users WHERE status = $1 AND age >= $2
+//	query := Rebind(Postgres, "SELECT id FROM users WHERE status = ? AND age >= ?")
+func Rebind(dialect Dialect, query string) string {
+	if dialect != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); {
+		if end := literalEnd(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end
+			continue
+		}
+		if query[i] == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		} else {
+			b.WriteByte(query[i])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// literalEnd returns the end of the quoted string or identifier, comment or
+// dollar-quoted string starting at query[i], or i if none starts there.
+// An unterminated one runs to the end of query.
+func literalEnd(query string, i int) int {
+	rest := query[i:]
+	switch {
+	case rest[0] == '\'' || rest[0] == '"':
+		// A doubled quote ends the literal and starts another one.
+		if j := strings.IndexByte(rest[1:], rest[0]); j >= 0 {
+			return i + j + 2
+		}
+	case strings.HasPrefix(rest, "--"):
+		if j := strings.IndexByte(rest, '\n'); j >= 0 {
+			return i + j
+		}
+	case strings.HasPrefix(rest, "/*"):
+		// Postgres block comments nest.
+		depth := 0
+		for j := 0; j+1 < len(rest); j++ {
+			switch rest[j : j+2] {
+			case "/*":
+				depth++
+				j++
+			case "*/":
+				depth--
+				j++
+				if depth == 0 {
+					return i + j + 1
+				}
+			}
+		}
+	case rest[0] == '$':
+		// $tag$ ... $tag$, where the tag is empty or an identifier. $1 is a
+		// parameter, and a $ inside an identifier, as in a$b, quotes nothing.
+		if i > 0 && isIdentByte(query[i-1]) {
+			return i
+		}
+		j := 1
+		for j < len(rest) && isIdentByte(rest[j]) && (j > 1 || rest[j] > '9') {
+			j++
+		}
+		if j == len(rest) || rest[j] != '$' {
+			return i
+		}
+		tag := rest[:j+1]
+		if k := strings.Index(rest[len(tag):], tag); k >= 0 {
+			return i + len(tag) + k + len(tag)
+		}
+	default:
+		return i
+	}
+	return len(query)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,28 @@
+package xsql
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM users WHERE a = ? AND b = ?", "SELECT id FROM users WHERE a = $1 AND b = $2"},
+		{"SELECT '?', 'it''s ?', \"a?\" FROM t WHERE a = ?", "SELECT '?', 'it''s ?', \"a?\" FROM t WHERE a = $1"},
+		{"SELECT a -- why?\nFROM t WHERE a = ?", "SELECT a -- why?\nFROM t WHERE a = $1"},
+		{"SELECT a /* b? /* nested? */ c? */ FROM t WHERE a = ?", "SELECT a /* b? /* nested? */ c? */ FROM t WHERE a = $1"},
+		{"SELECT $$?$$, $fn$ a ? $x$ $fn$ WHERE a = ?", "SELECT $$?$$, $fn$ a ? $x$ $fn$ WHERE a = $1"},
+		{"SELECT a$b FROM t WHERE a = ? AND b = ?", "SELECT a$b FROM t WHERE a = $1 AND b = $2"},
+		{"SELECT 1 WHERE a = ? -- unterminated?", "SELECT 1 WHERE a = $1 -- unterminated?"},
+		{"SELECT '?", "SELECT '?"},
+		{"SELECT $", "SELECT $"},
+	}
+	for _, tt := range tests {
+		if got := Rebind(Postgres, tt.query); got != tt.want {
+			t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+	if q := "SELECT ?"; Rebind(MySQL, q) != q {
+		t.Errorf("Rebind(MySQL) rewrote %q", q)
+	}
+}
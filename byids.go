@@ -0,0 +1,68 @@
+package xsql
+
+import (
+	"context"
+)
+
+// QueryByIDs fetches the rows of table whose idCol is in ids and returns them
+// in the order of ids, the classic dataloader batch fetch. keyOf returns the id
+// of a scanned row. The table and column names are quoted for dialect.
+//
+// Ids without a matching row are skipped, so the result may be shorter than ids.
+// An id given several times yields its row several times.
+// Empty ids return (nil, nil) without querying.
+//
+// Example:
+//
+//	users, err := QueryByIDs(ctx, db, Postgres, scanUser, func(u User) int64 {
+//		return u.ID
+//	}, "users", "id", []int64{3, 1, 2})
+func QueryByIDs[K comparable, T any](
+	ctx context.Context,
+	db DBTX,
+	dialect Dialect,
+	scan func(Scanner) (T, error),
+	keyOf func(T) K,
+	table, idCol string,
+	ids []K,
+) ([]T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	qTable, err := QuoteIdentifier(dialect, table)
+	if err != nil {
+		return nil, err
+	}
+	qCol, err := QuoteIdentifier(dialect, idCol)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[K]bool, len(ids))
+	unique := make([]K, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	placeholders, args := in(dialect, unique)
+
+	rows, err := QueryMany(ctx, db, scan, "SELECT * FROM "+qTable+" WHERE "+qCol+" IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[K]T, len(rows))
+	for _, row := range rows {
+		byID[keyOf(row)] = row
+	}
+	results := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if row, ok := byID[id]; ok {
+			results = append(results, row)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestQueryByIDs(t *testing.T) {
+	ctx := context.Background()
+	keyOf := func(id int64) int64 { return id }
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		table   string
+		col     string
+		query   string
+	}{
+		{"mysql", MySQL, "users", "id", "SELECT * FROM `users` WHERE `id` IN (?,?,?)"},
+		{"postgres", Postgres, "users", "id", `SELECT * FROM "users" WHERE "id" IN ($1,$2,$3)`},
+		{"quoted mysql", MySQL, "we`ird", "i`d", "SELECT * FROM `we``ird` WHERE `i``d` IN (?,?,?)"},
+		{"quoted postgres", Postgres, `we"ird`, `i"d`, `SELECT * FROM "we""ird" WHERE "i""d" IN ($1,$2,$3)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB().on(tt.query, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(3)}).open(t)
+
+			// Repeated ids are queried once, but come back in input order with
+			// the missing one skipped.
+			got, err := QueryByIDs(ctx, db, tt.dialect, ScanID[int64], keyOf, tt.table, tt.col, []int64{3, 2, 1, 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []int64{3, 1, 3}; !slices.Equal(got, want) {
+				t.Errorf("QueryByIDs = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// Neither runs a query, empty fails them.
+	empty := newFakeDB().open(t)
+	got, err := QueryByIDs(ctx, empty, Postgres, ScanID[int64], keyOf, "users", "id", nil)
+	if got != nil || err != nil {
+		t.Errorf("QueryByIDs without ids = %v, %v, want nil, nil", got, err)
+	}
+	if _, err := QueryByIDs(ctx, empty, Postgres, ScanID[int64], keyOf, "us\x00ers", "id", []int64{1}); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("QueryByIDs error = %v, want %v", err, ErrInvalidIdentifier)
+	}
+}
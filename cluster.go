@@ -0,0 +1,81 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// Cluster is a DBTX spreading reads over replicas.
+// ExecContext always runs on the primary, QueryContext and QueryRowContext
+// run on the replicas in round robin, or on the primary when there are none
+// or the context was marked with ForcePrimary.
+//
+// Example:
+//
+//	c := NewCluster(primary, replica1, replica2)
+//	users, err := QueryMany(ctx, c, scanUser, "SELECT id, name FROM users")
+type Cluster struct {
+	primary  DBTX
+	replicas []DBTX
+	next     atomic.Uint64
+}
+
+// NewCluster returns a Cluster writing to primary and reading from replicas.
+func NewCluster(primary DBTX, replicas ...DBTX) *Cluster {
+	return &Cluster{primary: primary, replicas: replicas}
+}
+
+type forcePrimaryKey struct{}
+
+// ForcePrimary returns a context which makes a Cluster run reads on the primary.
+//
+// Replicas lag behind the primary, so a read issued right after a write may not
+// see it. Marking the context of the request which wrote gives read-your-writes
+// without separate method names.
+// It only affects Cluster handles, other DBTX implementations ignore it.
+//
+// Example:
+//
+//	if _, err := c.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", name, id); err != nil {
+//		return err
+//	}
+//	ctx = ForcePrimary(ctx)
+//	user, err := QueryOne(ctx, c, scanUser, "SELECT id, name FROM users WHERE id = ?", id)
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+func isForcePrimary(ctx context.Context) bool {
+	force, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return force
+}
+
+// Primary returns the primary handle.
+func (c *Cluster) Primary() DBTX {
+	return c.primary
+}
+
+// ExecContext executes the statement on the primary.
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+// QueryContext runs the query on a replica, or on the primary for ForcePrimary contexts.
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs the query on a replica, or on the primary for ForcePrimary contexts.
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// reader picks the handle to run a read on.
+func (c *Cluster) reader(ctx context.Context) DBTX {
+	if len(c.replicas) == 0 || isForcePrimary(ctx) {
+		return c.primary
+	}
+	n := c.next.Add(1) - 1
+	return c.replicas[n%uint64(len(c.replicas))]
+}
@@ -0,0 +1,59 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"testing"
+)
+
+func TestClusterRouting(t *testing.T) {
+	ctx := context.Background()
+	named := func(name string) DBTX {
+		return newFakeDB().on("SELECT name", []string{"name"}, []driver.Value{name}).open(t)
+	}
+	primary, r1, r2 := named("primary"), named("r1"), named("r2")
+
+	tests := []struct {
+		name    string
+		cluster *Cluster
+		ctx     context.Context
+		want    []string
+	}{
+		{"round robin", NewCluster(primary, r1, r2), ctx, []string{"r1", "r2", "r1"}},
+		{"force primary", NewCluster(primary, r1, r2), ForcePrimary(ctx), []string{"primary", "primary"}},
+		{"no replicas", NewCluster(primary), ctx, []string{"primary", "primary"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				got, err := QueryOne(tt.ctx, tt.cluster, ScanID[string], "SELECT name")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("read %d ran on %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterExecOnPrimary(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := newFakeDB(), newFakeDB()
+	c := NewCluster(primary.open(t), replica.open(t))
+
+	if _, err := c.ExecContext(ForcePrimary(ctx), "DELETE FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ExecContext(ctx, "UPDATE users SET name = ''"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := primary.executed(), []string{"DELETE FROM users", "UPDATE users SET name = ''"}; !slices.Equal(got, want) {
+		t.Errorf("primary executed %q, want %q", got, want)
+	}
+	if got := replica.executed(); len(got) != 0 {
+		t.Errorf("replica executed %q", got)
+	}
+}
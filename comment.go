@@ -0,0 +1,77 @@
+package xsql
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// WithComment tags the query with a sqlcommenter comment,
+// e.g. /*route='%2Fusers',service='api'*/, which APM tools and
+// pg_stat_statements use to attribute queries to code.
+//
+// Keys are sorted, keys and values are URL encoded as the sqlcommenter
+// specification requires, so tags can't break out of the comment.
+// The comment is appended at the end of the statement, before a trailing semicolon,
+// on a new line after a trailing -- comment.
+// It is a no-op when tags is empty.
+//
+// Example:
+//
+//	users, err := QueryMany(ctx, db, scanUser, "SELECT id, name FROM users", WithComment(map[string]string{
+//		"route": "/users",
+//	}))
+func WithComment(tags map[string]string) Option {
+	return optionFunc(func(o *options) {
+		o.comment = sqlComment(tags)
+	})
+}
+
+func sqlComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(commentEscape(k))
+		b.WriteString("='")
+		b.WriteString(commentEscape(tags[k]))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	return b.String()
+}
+
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// addComment appends comment to query, keeping a trailing semicolon last.
+// The comment goes on a line of its own when the last line of query may hold
+// a -- comment, which would swallow it.
+func addComment(query, comment string) string {
+	if comment == "" {
+		return query
+	}
+	q := strings.TrimRight(query, " \t\r\n")
+	semicolon := ""
+	if strings.HasSuffix(q, ";") {
+		q, semicolon = strings.TrimSuffix(q, ";"), ";"
+	}
+	sep := " "
+	if strings.Contains(q[strings.LastIndexByte(q, '\n')+1:], "--") {
+		sep = "\n"
+	}
+	return q + sep + comment + semicolon
+}
@@ -0,0 +1,25 @@
+package xsql
+
+import "testing"
+
+func TestAddComment(t *testing.T) {
+	const c = "/*route='%2Fusers'*/"
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1 " + c},
+		{"SELECT 1;\n", "SELECT 1 " + c + ";"},
+		{"SELECT 1 -- one", "SELECT 1 -- one\n" + c},
+		{"SELECT 1 -- one\n;", "SELECT 1 -- one\n " + c + ";"},
+		{"-- users\nSELECT id FROM users", "-- users\nSELECT id FROM users " + c},
+	}
+	for _, tt := range tests {
+		if got := addComment(tt.query, c); got != tt.want {
+			t.Errorf("addComment(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+	if got := sqlComment(map[string]string{"route": "/users"}); got != c {
+		t.Errorf("sqlComment = %q, want %q", got, c)
+	}
+}
@@ -0,0 +1,51 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/freakshake/xerror"
+)
+
+// WithConn runs fn on a single connection taken from db and returns the
+// connection to the pool afterwards, even if fn panics.
+//
+// All of fn's statements run on the same physical connection, so session state
+// such as temporary tables, session advisory locks or SET variables carries over
+// from one to the next. A *sql.Conn is a DBTX, so it can be passed to every helper.
+// The session state is not reset, it stays on the connection once it is back in the pool.
+//
+// The connection is unavailable to the rest of the program while fn runs. Holding
+// connections for long, or from many goroutines at once, starves the pool, and other
+// queries wait for a free connection or fail with their context's deadline.
+// Keep fn short and don't do unrelated work like HTTP calls in it.
+//
+// Example:
+//
+//	err := WithConn(ctx, db, func(conn *sql.Conn) error {
+//		if _, err := conn.ExecContext(ctx, "CREATE TEMPORARY TABLE ids (id BIGINT)"); err != nil {
+//			return err
+//		}
+//		// ...fill ids...
+//		users, err = QueryMany(ctx, conn, scanUser, "SELECT u.id, u.name FROM users u JOIN ids USING (id)")
+//		return err
+//	})
+func WithConn(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	return withConn(conn, fn)
+}
+
+// withConn runs fn on conn and closes it afterwards.
+func withConn(conn *sql.Conn, fn func(conn *sql.Conn) error) (err error) {
+	defer func() {
+		cerr := conn.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "conn.Close(): %s", cerr.Error())
+		}
+	}()
+
+	return fn(conn)
+}
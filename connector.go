@@ -0,0 +1,77 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/freakshake/xerror"
+)
+
+// NewInitConnector returns a connector which runs the init statements on every
+// new physical connection opened by c, before handing it to the pool.
+// Use it with sql.OpenDB to set session settings like Postgres application_name
+// or SQLite pragmas.
+//
+// The statements run once per physical connection, not once per query:
+// connections reused from the pool keep the settings, and a statement which
+// changes them later lasts until that connection is closed.
+//
+// Example:
+//
+//	db := sql.OpenDB(NewInitConnector(connector, "SET TIME ZONE 'UTC'"))
+func NewInitConnector(c driver.Connector, init ...string) driver.Connector {
+	return &initConnector{Connector: c, init: init}
+}
+
+type initConnector struct {
+	driver.Connector
+	init []string
+}
+
+func (c *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.init {
+		if err := execConn(ctx, conn, stmt); err != nil {
+			_ = conn.Close()
+			xerror.Wrap(&err, "init statement %q", stmt)
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// execConn executes stmt on a driver connection.
+func execConn(ctx context.Context, conn driver.Conn, stmt string) error {
+	if ec, ok := conn.(driver.ExecerContext); ok {
+		_, err := ec.ExecContext(ctx, stmt, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if pc, ok := conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, stmt)
+	} else {
+		s, err = conn.Prepare(stmt)
+	}
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if sc, ok := s.(driver.StmtExecContext); ok {
+		_, err = sc.ExecContext(ctx, nil)
+		return err
+	}
+	// Drivers without StmtExecContext only have the deprecated Exec.
+	_, err = s.Exec(nil)
+	return err
+}
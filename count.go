@@ -0,0 +1,58 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/freakshake/xerror"
+)
+
+// QueryCountMap runs a grouped count query returning two columns, key and count,
+// and returns the counts keyed by the first column.
+// A key appearing twice means a malformed query and is reported as an error.
+//
+// Example:
+//
+//	counts, err := QueryCountMap[string](ctx, db, "SELECT status, COUNT(*) FROM orders GROUP BY status")
+//	if err != nil {
+//		panic(err)
+//	}
+//	fmt.Println(counts["paid"])
+func QueryCountMap[K comparable](
+	ctx context.Context,
+	db DBTX,
+	query string,
+	args ...any,
+) (_ map[K]int64, err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	counts := make(map[K]int64)
+
+	for rows.Next() {
+		var (
+			key   K
+			count int64
+		)
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, err
+		}
+		if _, ok := counts[key]; ok {
+			return nil, fmt.Errorf("xsql: QueryCountMap: duplicate key %v", key)
+		}
+		counts[key] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,60 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"maps"
+	"testing"
+)
+
+func TestQueryCountMap(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().
+		on("SELECT status, COUNT(*) FROM orders GROUP BY status", []string{"status", "count"},
+			[]driver.Value{"open", int64(2)},
+			[]driver.Value{"done", int64(3)},
+		).
+		on("SELECT priority, COUNT(*) FROM orders GROUP BY priority", []string{"priority", "count"},
+			[]driver.Value{int64(1), int64(2)},
+			[]driver.Value{int64(3), int64(1)},
+		).
+		on("SELECT status, 1 FROM orders", []string{"status", "1"},
+			[]driver.Value{"open", int64(1)},
+			[]driver.Value{"open", int64(1)},
+		).
+		on("SELECT status, COUNT(*) FROM empty GROUP BY status", []string{"status", "count"}).
+		open(t)
+
+	t.Run("string keys", func(t *testing.T) {
+		got, err := QueryCountMap[string](ctx, db, "SELECT status, COUNT(*) FROM orders GROUP BY status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]int64{"open": 2, "done": 3}; !maps.Equal(got, want) {
+			t.Errorf("QueryCountMap = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("int keys", func(t *testing.T) {
+		got, err := QueryCountMap[int](ctx, db, "SELECT priority, COUNT(*) FROM orders GROUP BY priority")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[int]int64{1: 2, 3: 1}; !maps.Equal(got, want) {
+			t.Errorf("QueryCountMap = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("duplicate key", func(t *testing.T) {
+		if _, err := QueryCountMap[string](ctx, db, "SELECT status, 1 FROM orders"); err == nil {
+			t.Error("QueryCountMap accepted a duplicate key")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := QueryCountMap[string](ctx, db, "SELECT status, COUNT(*) FROM empty GROUP BY status")
+		if err != nil || got == nil || len(got) != 0 {
+			t.Errorf("QueryCountMap = %v, %v, want an empty map", got, err)
+		}
+	})
+}
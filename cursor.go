@@ -0,0 +1,44 @@
+package xsql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned by DecodeCursor for malformed or truncated tokens.
+var ErrInvalidCursor = errors.New("xsql: invalid cursor")
+
+// EncodeCursor returns an opaque pagination token for v,
+// the URL safe base64 encoding of its JSON representation.
+// Clients can't depend on the cursor shape, so it can be changed safely.
+//
+// Example:
+//
+//	type userCursor struct {
+//		CreatedAt time.Time `json:"c"`
+//		ID        int64     `json:"i"`
+//	}
+//	next, err := EncodeCursor(userCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+func EncodeCursor(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes a token returned by EncodeCursor into a T.
+// Tokens which are not valid base64 or JSON, e.g. truncated ones,
+// are reported with an error wrapping ErrInvalidCursor.
+func DecodeCursor[T any](s string) (v T, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return v, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return v, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	return v, nil
+}
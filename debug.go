@@ -0,0 +1,35 @@
+package xsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Debug enables extra checks which catch programming mistakes early
+// at the cost of some speed. It is meant for development and tests,
+// keep it off in production. Set it before issuing any query.
+var Debug bool
+
+// ValidateArgs checks that every arg can be passed to a driver, i.e. it is a
+// driver.Valuer or a type the database/sql default converter accepts, and reports
+// the first offending arg with its index and type. This turns a cryptic error
+// from deep in the driver into an actionable one.
+//
+// In Debug mode QueryOne and QueryMany validate their args before running the query.
+// Drivers with their own argument checks may accept more types, e.g. Postgres arrays,
+// so only enable it where the args use the standard types.
+func ValidateArgs(args []any) error {
+	for i, arg := range args {
+		if na, ok := arg.(sql.NamedArg); ok {
+			if _, ok := na.Value.(sql.Out); ok {
+				continue
+			}
+			arg = na.Value
+		}
+		if _, err := driver.DefaultParameterConverter.ConvertValue(arg); err != nil {
+			return fmt.Errorf("xsql: arg %d of type %T is not a driver value: %w", i, arg, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package xsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, meant for NUMERIC and DECIMAL columns
+// like money amounts, which lose precision when scanned into float64.
+// The scale of the database value is preserved, e.g. "10.50" stays "10.50".
+//
+// Decimal implements sql.Scanner and driver.Valuer. Scan accepts []byte, string,
+// int64 and float64 sources, Value produces the exact decimal string.
+// Scan a nullable column into a *Decimal.
+//
+// The zero value is 0.
+//
+// Example:
+//
+//	var balance Decimal
+//	err := Row(ctx, db, "SELECT balance FROM accounts WHERE id = ?", []any{1}, &balance)
+type Decimal struct {
+	// unscaled is the value times 10^scale, nil means 0.
+	unscaled *big.Int
+	scale    int
+}
+
+// ParseDecimal parses a decimal string like "-12.340".
+// Exponents, NaN and infinities are not accepted.
+func ParseDecimal(s string) (Decimal, error) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	intPart, frac, _ := strings.Cut(digits, ".")
+	if intPart == "" && frac == "" || !isDigits(intPart) || !isDigits(frac) {
+		return Decimal{}, fmt.Errorf("xsql: invalid decimal %q", s)
+	}
+
+	u, ok := new(big.Int).SetString(intPart+frac, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("xsql: invalid decimal %q", s)
+	}
+	if s[0] == '-' {
+		u.Neg(u)
+	}
+	return Decimal{unscaled: u, scale: len(frac)}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the exact decimal representation of d.
+func (d Decimal) String() string {
+	if d.unscaled == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Rat returns d as an exact rational number.
+func (d Decimal) Rat() *big.Rat {
+	if d.unscaled == nil {
+		return new(big.Rat)
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.unscaled, denom)
+}
+
+// Scan implements sql.Scanner.
+func (d *Decimal) Scan(src any) error {
+	var (
+		v   Decimal
+		err error
+	)
+	switch s := src.(type) {
+	case []byte:
+		v, err = ParseDecimal(string(s))
+	case string:
+		v, err = ParseDecimal(s)
+	case int64:
+		v = Decimal{unscaled: big.NewInt(s)}
+	case float64:
+		v, err = ParseDecimal(strconv.FormatFloat(s, 'f', -1, 64))
+	case nil:
+		return fmt.Errorf("xsql: can not scan NULL into Decimal, use *Decimal")
+	default:
+		return fmt.Errorf("xsql: can not scan %T into Decimal", src)
+	}
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,123 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		scale int
+		bad   bool
+	}{
+		{in: "0", want: "0"},
+		{in: "10.50", want: "10.50", scale: 2},
+		{in: "-12.340", want: "-12.340", scale: 3},
+		{in: "+7", want: "7"},
+		{in: ".5", want: "0.5", scale: 1},
+		{in: "-0.001", want: "-0.001", scale: 3},
+		{in: "12345678901234567890.123456789012345678", want: "12345678901234567890.123456789012345678", scale: 18},
+		{in: "", bad: true},
+		{in: "-", bad: true},
+		{in: ".", bad: true},
+		{in: "1e5", bad: true},
+		{in: "1.2.3", bad: true},
+		{in: "NaN", bad: true},
+		{in: " 1", bad: true},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if tt.bad {
+			if err == nil {
+				t.Errorf("ParseDecimal(%q) = %s, want an error", tt.in, d)
+			}
+			continue
+		}
+		if err != nil || d.String() != tt.want || d.Scale() != tt.scale {
+			t.Errorf("ParseDecimal(%q) = %s (scale %d), %v, want %s (scale %d)", tt.in, d, d.Scale(), err, tt.want, tt.scale)
+		}
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want string
+		bad  bool
+	}{
+		{src: []byte("1234.5678"), want: "1234.5678"},
+		{src: "0.10", want: "0.10"},
+		{src: int64(-42), want: "-42"},
+		{src: 0.1, want: "0.1"},
+		{src: 1e21, want: "1000000000000000000000"},
+		{src: nil, bad: true},
+		{src: true, bad: true},
+		{src: "abc", bad: true},
+	}
+	for _, tt := range tests {
+		var d Decimal
+		err := d.Scan(tt.src)
+		if tt.bad {
+			if err == nil {
+				t.Errorf("Scan(%#v) = %s, want an error", tt.src, d)
+			}
+			continue
+		}
+		if err != nil || d.String() != tt.want {
+			t.Errorf("Scan(%#v) = %s, %v, want %s", tt.src, d, err, tt.want)
+		}
+	}
+}
+
+func TestDecimalRat(t *testing.T) {
+	d, err := ParseDecimal("-1.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewRat(-5, 4); d.Rat().Cmp(want) != 0 {
+		t.Errorf("Rat = %s, want %s", d.Rat(), want)
+	}
+	if (Decimal{}).Rat().Sign() != 0 {
+		t.Error("Rat of the zero Decimal is not 0")
+	}
+}
+
+func TestDecimalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	// More digits than a float64 holds.
+	const exact = "98765432109876543210.000000000000000001"
+	in, err := ParseDecimal(exact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := in.Value()
+	if err != nil || v != exact {
+		t.Fatalf("Value = %v, %v, want %s", v, err, exact)
+	}
+
+	db := newFakeDB().
+		on("SELECT balance", []string{"balance"}, []driver.Value{[]byte(exact)}).
+		on("SELECT NULL", []string{"balance"}, []driver.Value{nil}).
+		open(t)
+
+	var out Decimal
+	if err := Row(ctx, db, "SELECT balance", nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != exact {
+		t.Errorf("round trip = %s, want %s", out, exact)
+	}
+
+	var null *Decimal
+	if err := Row(ctx, db, "SELECT NULL", nil, &null); err != nil || null != nil {
+		t.Errorf("scanning NULL into *Decimal = %v, %v, want nil", null, err)
+	}
+	if err := Row(ctx, db, "SELECT NULL", nil, &out); err == nil {
+		t.Error("scanning NULL into Decimal succeeded")
+	}
+}
@@ -0,0 +1,57 @@
+package xsql
+
+import (
+	"errors"
+	"strings"
+)
+
+// Dialect is the SQL flavour spoken by a database.
+// It decides how identifiers are quoted and how placeholders are written.
+type Dialect int
+
+const (
+	// MySQL quotes identifiers with backticks and uses ? placeholders.
+	MySQL Dialect = iota
+	// Postgres quotes identifiers with double quotes and uses $n placeholders.
+	Postgres
+)
+
+// ErrInvalidIdentifier is returned when an identifier can not be quoted safely.
+var ErrInvalidIdentifier = errors.New("xsql: invalid identifier")
+
+// QuoteIdentifier wraps ident in the quote character of the dialect,
+// backticks for MySQL and double quotes for Postgres, and escapes any
+// embedded quote character by doubling it.
+// Identifiers which are empty or contain a null byte are rejected with ErrInvalidIdentifier.
+//
+// Every helper which interpolates table or column names into a query must
+// route them through QuoteIdentifier.
+//
+// Example:
+//
+//	// `my``table`
+//	q, err := QuoteIdentifier(MySQL, "my`table")
+//	// "my""table"
+//	q, err = QuoteIdentifier(Postgres, `my"table`)
+func QuoteIdentifier(dialect Dialect, ident string) (string, error) {
+	if ident == "" || strings.IndexByte(ident, 0) >= 0 {
+		return "", ErrInvalidIdentifier
+	}
+	q := dialect.quote()
+	return q + strings.ReplaceAll(ident, q, q+q) + q, nil
+}
+
+func (d Dialect) quote() string {
+	if d == Postgres {
+		return `"`
+	}
+	return "`"
+}
+
+// in returns the IN placeholder list for values in the placeholder style of d.
+func in[T any](d Dialect, values []T) (string, []any) {
+	if d == Postgres {
+		return InN(1, values)
+	}
+	return In(values)
+}
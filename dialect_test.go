@@ -0,0 +1,32 @@
+package xsql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		ident   string
+		want    string
+		err     error
+	}{
+		{MySQL, "users", "`users`", nil},
+		{MySQL, "my`table", "`my``table`", nil},
+		{MySQL, "``", "``````", nil},
+		{MySQL, `my"table`, "`my\"table`", nil},
+		{Postgres, "users", `"users"`, nil},
+		{Postgres, `my"table`, `"my""table"`, nil},
+		{Postgres, `"; DROP TABLE users; --`, `"""; DROP TABLE users; --"`, nil},
+		{Postgres, "my`table", "\"my`table\"", nil},
+		{MySQL, "", "", ErrInvalidIdentifier},
+		{Postgres, "us\x00ers", "", ErrInvalidIdentifier},
+	}
+	for _, tt := range tests {
+		got, err := QuoteIdentifier(tt.dialect, tt.ident)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("QuoteIdentifier(%d, %q) = %q, %v, want %q, %v", tt.dialect, tt.ident, got, err, tt.want, tt.err)
+		}
+	}
+}
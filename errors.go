@@ -0,0 +1,120 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+)
+
+var (
+	// ErrNotFound is returned when a query which must return a row returned none.
+	// It wraps sql.ErrNoRows, so errors.Is(err, sql.ErrNoRows) keeps working.
+	ErrNotFound = fmt.Errorf("xsql: not found: %w", sql.ErrNoRows)
+	// ErrNullResult is returned by ScanNonNull when the scanned value is SQL NULL,
+	// e.g. SELECT MAX(x) on an empty table.
+	ErrNullResult = errors.New("xsql: null result")
+)
+
+// notFound translates sql.ErrNoRows to ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// IsUnavailable reports whether err is a connection level failure, meaning the
+// database could not be reached or the connection broke, rather than an error
+// of the statement itself. Context cancellation and deadlines are not
+// connection failures, they are reported as false.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ErrorMapper translates a database error into a domain error,
+// e.g. a unique violation into ErrEmailTaken.
+// It returns err itself or nil for errors it doesn't translate.
+type ErrorMapper func(err error) error
+
+var globalMapper atomic.Pointer[ErrorMapper]
+
+// SetErrorMapper sets the ErrorMapper applied to the errors of every QueryOne
+// and QueryMany call which doesn't pass its own MapErrors option.
+// A nil m removes it.
+func SetErrorMapper(m ErrorMapper) {
+	if m == nil {
+		globalMapper.Store(nil)
+		return
+	}
+	globalMapper.Store(&m)
+}
+
+// MapErrors makes a single QueryOne or QueryMany call translate its errors with m,
+// instead of the ErrorMapper set by SetErrorMapper.
+func MapErrors(m ErrorMapper) Option {
+	return optionFunc(func(o *options) {
+		o.mapErr = m
+	})
+}
+
+// MappedError is an error translated by an ErrorMapper.
+// It reads as the mapped error and matches it with errors.Is and errors.As,
+// while errors.Unwrap still returns the original error.
+type MappedError struct {
+	// Err is the error returned by the ErrorMapper.
+	Err error
+	// Cause is the original error.
+	Cause error
+}
+
+func (e *MappedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MappedError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *MappedError) Is(target error) bool {
+	return errors.Is(e.Err, target)
+}
+
+func (e *MappedError) As(target any) bool {
+	return errors.As(e.Err, target)
+}
+
+// mapError runs err through the ErrorMapper of the call or the global one.
+func mapError(o options, err error) error {
+	if err == nil {
+		return nil
+	}
+	m := o.mapErr
+	if m == nil {
+		if p := globalMapper.Load(); p != nil {
+			m = *p
+		}
+	}
+	if m == nil {
+		return err
+	}
+	mapped := m(err)
+	if mapped == nil || mapped == err {
+		return err
+	}
+	return &MappedError{Err: mapped, Cause: err}
+}
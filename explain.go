@@ -0,0 +1,79 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// explainTimeout bounds the EXPLAIN run by ExplainHook.
+const explainTimeout = 10 * time.Second
+
+// ExplainHook is a Hook which captures the plan of slow queries.
+// Create it with NewExplainHook.
+type ExplainHook struct {
+	db        DBTX
+	threshold time.Duration
+	rate      float64
+	logger    *slog.Logger
+}
+
+// NewExplainHook returns a Hook which re-runs queries slower than threshold
+// with EXPLAIN on db, a replica or the primary, and logs the plan with logger
+// at warn level. A nil logger means slog.Default().
+//
+// Only a rate (0 to 1) fraction of the slow queries is explained, to cap the overhead,
+// e.g. 0.01 explains 1% of them. Only SELECT statements are explained, since
+// re-running anything else could change data, and EXPLAIN statements are never
+// explained again, so db may be hooked itself.
+// The EXPLAIN runs in its own goroutine and doesn't delay the caller.
+func NewExplainHook(db DBTX, threshold time.Duration, rate float64, logger *slog.Logger) *ExplainHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ExplainHook{db: db, threshold: threshold, rate: rate, logger: logger}
+}
+
+// Before implements Hook.
+func (h *ExplainHook) Before(context.Context, QueryEvent) {}
+
+// After implements Hook.
+func (h *ExplainHook) After(ctx context.Context, e QueryEvent) {
+	if e.Err != nil || e.Duration < h.threshold || !isSelect(e.Query) {
+		return
+	}
+	if rand.Float64() >= h.rate {
+		return
+	}
+
+	args := slices.Clone(e.Args)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), explainTimeout)
+		defer cancel()
+
+		_, _, plan, err := QueryTable(ctx, h.db, "EXPLAIN "+e.Query, args...)
+		if err != nil {
+			h.logger.WarnContext(ctx, "xsql: explain slow query", slog.String("query", e.Query), slog.Any("error", err))
+			return
+		}
+		lines := make([]string, len(plan))
+		for i, row := range plan {
+			lines[i] = strings.TrimSpace(fmt.Sprintln(row...))
+		}
+		h.logger.WarnContext(ctx, "xsql: slow query plan",
+			slog.String("query", e.Query),
+			slog.Duration("duration", e.Duration),
+			slog.String("plan", strings.Join(lines, "\n")),
+		)
+	}()
+}
+
+// isSelect reports whether query is a SELECT statement.
+func isSelect(query string) bool {
+	q := strings.TrimLeft(query, " \t\r\n(")
+	return len(q) >= 6 && strings.EqualFold(q[:6], "SELECT")
+}
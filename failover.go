@@ -0,0 +1,147 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrNoHealthyHandle is returned by Failover.HealthCheck when no handle answered.
+var ErrNoHealthyHandle = errors.New("xsql: no healthy handle")
+
+// Failover is a DBTX running statements on the current primary of an ordered list
+// of handles, and moving reads on to the next handles when the primary is unavailable.
+// Create it with NewFailover.
+//
+// A read which fails with an error for which IsUnavailable is true is retried on
+// the other handles in turn, in list order after the primary, the first to answer wins. Writes only fail over
+// with FailoverWrites. Failing over doesn't change the primary, that is left to
+// Promote and HealthCheck, so a brief network hiccup doesn't move all traffic.
+//
+// Consistency caveats: a standby lags behind the primary, so reads which failed
+// over may miss recent writes. A primary which is unreachable from this process may
+// still be up for others, and writes failing over to a standby which accepts them can
+// split the data between two databases. Only enable FailoverWrites when the standby
+// refuses writes until it is promoted by the database itself.
+//
+// Example:
+//
+//	f := NewFailover([]*sql.DB{primary, standby})
+//	go func() {
+//		for range time.Tick(5 * time.Second) {
+//			_, _ = f.HealthCheck(ctx)
+//		}
+//	}()
+//	users, err := QueryMany(ctx, f, scanUser, "SELECT id, name FROM users")
+type Failover struct {
+	dbs     []*sql.DB
+	current atomic.Int64
+	writes  bool
+}
+
+// FailoverOption configures a Failover.
+type FailoverOption func(*Failover)
+
+// FailoverWrites makes a Failover fail over writes too, see the caveats of Failover.
+func FailoverWrites() FailoverOption {
+	return func(f *Failover) {
+		f.writes = true
+	}
+}
+
+// NewFailover returns a Failover over dbs, in order of preference.
+// The first handle is the primary. It panics if dbs is empty.
+func NewFailover(dbs []*sql.DB, opts ...FailoverOption) *Failover {
+	if len(dbs) == 0 {
+		panic("xsql: NewFailover needs at least one handle")
+	}
+	f := &Failover{dbs: dbs}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Current returns the index of the current primary.
+func (f *Failover) Current() int {
+	return int(f.current.Load())
+}
+
+// Primary returns the current primary handle.
+func (f *Failover) Primary() *sql.DB {
+	return f.dbs[f.Current()]
+}
+
+// Promote makes the handle at index i the primary. It panics if i is out of range.
+func (f *Failover) Promote(i int) {
+	_ = f.dbs[i]
+	f.current.Store(int64(i))
+}
+
+// HealthCheck pings the handles in order of preference and promotes the first
+// which answers, so the primary fails back once a preferred handle recovers.
+// It returns the index of the new primary, or ErrNoHealthyHandle and keeps the
+// current primary when none answered.
+func (f *Failover) HealthCheck(ctx context.Context) (int, error) {
+	for i, db := range f.dbs {
+		if err := db.PingContext(ctx); err == nil {
+			f.Promote(i)
+			return i, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return f.Current(), err
+		}
+	}
+	return f.Current(), ErrNoHealthyHandle
+}
+
+// ExecContext executes the statement on the primary.
+// With FailoverWrites it moves on to the next handles while the error is IsUnavailable.
+func (f *Failover) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if !f.writes {
+		return f.Primary().ExecContext(ctx, query, args...)
+	}
+	var res sql.Result
+	err := f.try(ctx, func(db *sql.DB) (err error) {
+		res, err = db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// QueryContext runs the query on the primary,
+// moving on to the next handles while the error is IsUnavailable.
+func (f *Failover) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := f.try(ctx, func(db *sql.DB) (err error) {
+		rows, err = db.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext runs the query on the primary,
+// moving on to the next handles while the error is IsUnavailable.
+func (f *Failover) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	var row *sql.Row
+	_ = f.try(ctx, func(db *sql.DB) error {
+		row = db.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// try calls fn with the primary and then with each following handle,
+// wrapping around, until it returns an error which is not IsUnavailable.
+func (f *Failover) try(ctx context.Context, fn func(*sql.DB) error) error {
+	start := f.Current()
+	var err error
+	for i := range f.dbs {
+		err = fn(f.dbs[(start+i)%len(f.dbs)])
+		if !IsUnavailable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package xsql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Filter builds a Where from params, a struct (or pointer to one) whose fields
+// describe optional search filters with a `filter` tag:
+//
+//	`filter:"column"`          column = value
+//	`filter:"column,op"`       column op value, op is one of =, <>, <, <=, >, >=
+//	`filter:"column,like"`     column LIKE value, the value holds the wildcards
+//	`filter:"column,in"`       column IN (values...), the field is a slice
+//	`filter:"column,between"`  column BETWEEN v[0] AND v[1], the field is a slice or array of two
+//
+// Fields holding their zero value, nil pointers and empty slices are skipped,
+// so only the filters which were set end up in the clause. Use a pointer field
+// to filter on a zero value. Fields without a tag are ignored. A tag on an
+// unexported field is an error, as its filter could not be applied.
+//
+// Columns come from the struct tags, never from user input, so they are
+// written as is and may be qualified, e.g. "u.name".
+//
+// Example:
+//
+//	type UserSearch struct {
+//		Status  string    `filter:"status"`
+//		Name    string    `filter:"name,like"`
+//		IDs     []int64   `filter:"id,in"`
+//		MinAge  int       `filter:"age,>="`
+//		Created [2]string `filter:"created_at,between"`
+//	}
+//	w, err := Filter(UserSearch{Status: "active", IDs: []int64{1, 2}})
+//	if err != nil {
+//		panic(err)
+//	}
+//	// WHERE (status = ?) AND (id IN (?,?))
+//	clause, args := w.Build()
+func Filter(params any) (*Where, error) {
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return NewWhere(), nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("xsql: Filter: %T is not a struct", params)
+	}
+
+	w := NewWhere()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("filter")
+		if !ok || tag == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, fmt.Errorf("xsql: Filter: field %s is unexported", field.Name)
+		}
+		column, op, _ := strings.Cut(tag, ",")
+		if err := addFilter(w, column, strings.ToUpper(op), v.Field(i)); err != nil {
+			return nil, fmt.Errorf("xsql: Filter: field %s: %w", field.Name, err)
+		}
+	}
+	return w, nil
+}
+
+func addFilter(w *Where, column, op string, f reflect.Value) error {
+	if f.IsZero() {
+		return nil
+	}
+	for f.Kind() == reflect.Pointer {
+		f = f.Elem()
+	}
+
+	switch op {
+	case "", "=", "<>", "<", "<=", ">", ">=":
+		if op == "" {
+			op = "="
+		}
+		w.And(column+" "+op+" ?", f.Interface())
+	case "LIKE":
+		w.And(column+" LIKE ?", f.Interface())
+	case "IN":
+		if f.Kind() != reflect.Slice && f.Kind() != reflect.Array {
+			return fmt.Errorf("in needs a slice, got %s", f.Type())
+		}
+		if f.Len() == 0 {
+			return nil
+		}
+		args := make([]any, f.Len())
+		for i := range args {
+			args[i] = f.Index(i).Interface()
+		}
+		placeholders, _ := In(args)
+		w.And(column+" IN ("+placeholders+")", args...)
+	case "BETWEEN":
+		if f.Kind() == reflect.Slice && f.Len() == 0 {
+			return nil
+		}
+		if (f.Kind() != reflect.Slice && f.Kind() != reflect.Array) || f.Len() != 2 {
+			return fmt.Errorf("between needs a slice or array of two values, got %s", f.Type())
+		}
+		w.And(column+" BETWEEN ? AND ?", f.Index(0).Interface(), f.Index(1).Interface())
+	default:
+		return fmt.Errorf("unknown operator %q", op)
+	}
+	return nil
+}
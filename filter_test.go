@@ -0,0 +1,54 @@
+package xsql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	type search struct {
+		Status  string    `filter:"status"`
+		Name    string    `filter:"name,like"`
+		IDs     []int64   `filter:"id,in"`
+		MinAge  *int      `filter:"age,>="`
+		Created [2]string `filter:"created_at,between"`
+		Page    int
+	}
+	zero := 0
+
+	w, err := Filter(&search{Status: "active", IDs: []int64{1, 2}, MinAge: &zero, Page: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clause, args := w.Build()
+	if want := "WHERE (status = ?) AND (id IN (?,?)) AND (age >= ?)"; clause != want {
+		t.Errorf("clause = %q, want %q", clause, want)
+	}
+	if want := []any{"active", int64(1), int64(2), 0}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params any
+		err    string
+	}{
+		{"not a struct", 1, "is not a struct"},
+		{"unexported field", struct {
+			tenant int64 `filter:"tenant_id"`
+		}{tenant: 7}, "field tenant is unexported"},
+		{"unknown operator", struct {
+			Age int `filter:"age,~"`
+		}{Age: 1}, `unknown operator "~"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Filter(tt.params); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Filter error = %v, want one holding %q", err, tt.err)
+			}
+		})
+	}
+}
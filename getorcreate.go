@@ -0,0 +1,72 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GetOrCreate inserts a row unless it already exists and returns it,
+// reporting whether it was created by this call.
+//
+// insertSQL must ignore conflicts, with the dialect specific syntax:
+//
+//	Postgres, SQLite: INSERT INTO users (email) VALUES ($1) ON CONFLICT (email) DO NOTHING
+//	MySQL:            INSERT IGNORE INTO users (email) VALUES (?)
+//
+// selectSQL must return the row, it is scanned with scanExisting.
+// Both statements are run with args, inside a single transaction.
+//
+// If the insert fails anyway, e.g. with a unique violation because the
+// conflict clause is missing, the row is looked up again outside of the
+// aborted transaction and returned when found; otherwise the insert error is returned.
+//
+// Example:
+//
+//	user, created, err := GetOrCreate(ctx, db, scanUser,
+//		"INSERT INTO users (email) VALUES ($1) ON CONFLICT (email) DO NOTHING",
+//		"SELECT id, email FROM users WHERE email = $1",
+//		"bob@example.com",
+//	)
+func GetOrCreate[T any](
+	ctx context.Context,
+	db *sql.DB,
+	scanExisting func(Scanner) (T, error),
+	insertSQL string,
+	selectSQL string,
+	args ...any,
+) (_ T, created bool, err error) {
+	var zero T
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return zero, false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, insertSQL, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		if existing, serr := QueryOne(ctx, db, scanExisting, selectSQL, args...); serr == nil {
+			return existing, false, nil
+		}
+		return zero, false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return zero, false, err
+	}
+
+	v, err := QueryOne(ctx, tx, scanExisting, selectSQL, args...)
+	if err != nil {
+		return zero, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return zero, false, err
+	}
+
+	return v, n > 0, nil
+}
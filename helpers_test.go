@@ -0,0 +1,271 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// openTestDB returns an in-memory SQLite database closed at the end of the test.
+// It holds a single connection, so all statements see the same database.
+func openTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// mustExec runs the statements on db and fails the test on error.
+func mustExec(t testing.TB, db DBTX, stmts ...string) {
+	t.Helper()
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+// registerDecoder registers dec in DefaultRegistry for the duration of the test,
+// restoring the decoder it replaces, if any, at the end.
+func registerDecoder(t testing.TB, typeName string, dec Decoder) {
+	t.Helper()
+	prev, ok := DefaultRegistry.Lookup(typeName)
+	DefaultRegistry.Register(typeName, dec)
+	t.Cleanup(func() {
+		if ok {
+			DefaultRegistry.Register(typeName, prev)
+			return
+		}
+		DefaultRegistry.mu.Lock()
+		defer DefaultRegistry.mu.Unlock()
+		delete(DefaultRegistry.decoders, strings.ToUpper(typeName))
+	})
+}
+
+// fakeDB is an in-memory database/sql driver for the tests. It runs no SQL,
+// a query returns the rows registered for it with on.
+type fakeDB struct {
+	mu      sync.Mutex
+	results map[string]fakeResult
+	// closed counts the closed prepared statements per query.
+	closed map[string]int
+	// execs holds the executed statements, in order.
+	execs []string
+	// commits and rollbacks count the ended transactions.
+	commits, rollbacks int
+	// read counts the rows read by database/sql.
+	read int
+}
+
+type fakeResult struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{results: make(map[string]fakeResult), closed: make(map[string]int)}
+}
+
+// on makes query return rows, made of the columns cols.
+// Queries without rows registered fail.
+func (f *fakeDB) on(query string, cols []string, rows ...[]driver.Value) *fakeDB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results[query] = fakeResult{cols: cols, rows: rows}
+	return f
+}
+
+// open returns a sql.DB on f closed at the end of the test.
+func (f *fakeDB) open(t testing.TB) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{f})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (f *fakeDB) query(query string) (driver.Rows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res, ok := f.results[query]
+	if !ok {
+		return nil, fmt.Errorf("fake: unexpected query %q", query)
+	}
+	return &fakeRows{f: f, res: res}, nil
+}
+
+// executed returns the statements executed so far.
+func (f *fakeDB) executed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.execs...)
+}
+
+func (f *fakeDB) exec(query string) (driver.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+// rowsRead returns the number of rows read so far.
+func (f *fakeDB) rowsRead() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read
+}
+
+// txs returns the number of committed and rolled back transactions.
+func (f *fakeDB) txs() (commits, rollbacks int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commits, f.rollbacks
+}
+
+// stmtsClosed returns the number of closed prepared statements for query.
+func (f *fakeDB) stmtsClosed(query string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed[query]
+}
+
+type fakeConnector struct{ f *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (fakeConnector) Driver() driver.Driver                          { return nil }
+
+type fakeConn struct{ f *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{f: c.f, query: query}, nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx(c), nil
+}
+
+func (fakeConn) Close() error { return nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return c.f.query(query)
+}
+
+// ExecContext fills each OUT parameter outN with the argument inN.
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	in := make(map[string]any)
+	for _, arg := range args {
+		if _, ok := arg.Value.(sql.Out); !ok {
+			in[arg.Name] = arg.Value
+		}
+	}
+	for _, arg := range args {
+		if out, ok := arg.Value.(sql.Out); ok {
+			*out.Dest.(*any) = in["in"+strings.TrimPrefix(arg.Name, "out")]
+		}
+	}
+	return c.f.exec(query)
+}
+
+// CheckNamedValue accepts every argument, so sql.Out reaches ExecContext.
+func (fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type fakeTx struct{ f *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.f.mu.Lock()
+	defer tx.f.mu.Unlock()
+	tx.f.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.f.mu.Lock()
+	defer tx.f.mu.Unlock()
+	tx.f.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	f     *fakeDB
+	query string
+}
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return s.f.exec(s.query)
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.f.query(s.query)
+}
+
+func (s *fakeStmt) Close() error {
+	s.f.mu.Lock()
+	defer s.f.mu.Unlock()
+	s.f.closed[s.query]++
+	return nil
+}
+
+type fakeRows struct {
+	f    *fakeDB
+	res  fakeResult
+	next int
+}
+
+func (r *fakeRows) Columns() []string { return r.res.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.res.rows) {
+		return io.EOF
+	}
+	copy(dest, r.res.rows[r.next])
+	r.next++
+	r.f.mu.Lock()
+	r.f.read++
+	r.f.mu.Unlock()
+	return nil
+}
+
+// nopDB is a DBTX doing nothing.
+type nopDB struct{}
+
+func (nopDB) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (nopDB) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (nopDB) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return &sql.Row{}
+}
+
+// hookFuncs is a Hook calling its functions.
+type hookFuncs struct {
+	before, after func(QueryEvent)
+}
+
+func (h hookFuncs) Before(_ context.Context, e QueryEvent) {
+	if h.before != nil {
+		h.before(e)
+	}
+}
+
+func (h hookFuncs) After(_ context.Context, e QueryEvent) {
+	if h.after != nil {
+		h.after(e)
+	}
+}
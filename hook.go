@@ -0,0 +1,106 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryEvent describes a statement issued through a DBTX wrapped by WithHooks.
+type QueryEvent struct {
+	// Op is the DBTX method used: "exec", "query" or "query_row".
+	Op    string
+	Query string
+	// Args are the statement's args, the caller's own slice. Hooks must not
+	// modify it or keep it once they returned, as the caller may reuse it, e.g.
+	// with PutArgs. A hook using the args later, e.g. in a goroutine, has to
+	// slices.Clone them first.
+	Args []any
+	// Start is when the statement was issued.
+	Start time.Time
+	// Duration is how long the DBTX method took. For queries it does not
+	// include reading the rows. It is only set for After.
+	Duration time.Duration
+	// Err is the error the statement failed with. It is only set for After.
+	Err error
+	// Result is the result of an exec. It is only set for After.
+	Result sql.Result
+}
+
+// Hook observes the statements issued through a DBTX wrapped by WithHooks.
+// Hooks are called synchronously, in registration order, so they must be fast.
+type Hook interface {
+	// Before is called right before the statement is issued.
+	Before(ctx context.Context, e QueryEvent)
+	// After is called once the statement returned.
+	After(ctx context.Context, e QueryEvent)
+}
+
+// Hooked is a DBTX calling hooks around every statement.
+type Hooked struct {
+	db    DBTX
+	hooks []Hook
+}
+
+// WithHooks returns a DBTX which calls hooks around every statement issued through it.
+//
+// Example:
+//
+//	db := WithHooks(sqlDB, NewExplainHook(replica, time.Second, 0.01, nil))
+//	users, err := QueryMany(ctx, db, scanUser, "SELECT id, name FROM users")
+func WithHooks(db DBTX, hooks ...Hook) *Hooked {
+	return &Hooked{db: db, hooks: hooks}
+}
+
+// ExecContext executes the statement on the wrapped DBTX.
+func (h *Hooked) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e := h.before(ctx, "exec", query, args)
+	res, err := h.db.ExecContext(ctx, query, args...)
+	e.Result = res
+	h.after(ctx, e, err)
+	return res, err
+}
+
+// QueryContext runs the query on the wrapped DBTX.
+func (h *Hooked) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	e := h.before(ctx, "query", query, args)
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	h.after(ctx, e, err)
+	return rows, err
+}
+
+// QueryRowContext runs the query on the wrapped DBTX.
+func (h *Hooked) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	e := h.before(ctx, "query_row", query, args)
+	row := h.db.QueryRowContext(ctx, query, args...)
+	h.after(ctx, e, row.Err())
+	return row
+}
+
+// QueryRowErrContext implements RowErrQuerier, so the errors of a wrapped
+// RowErrQuerier reach QueryOne through h.
+func (h *Hooked) QueryRowErrContext(ctx context.Context, query string, args ...any) (*sql.Row, error) {
+	e := h.before(ctx, "query_row", query, args)
+	row, err := queryRow(ctx, h.db, query, args)
+	if err == nil {
+		err = row.Err()
+	}
+	h.after(ctx, e, err)
+	return row, err
+}
+
+func (h *Hooked) before(ctx context.Context, op, query string, args []any) QueryEvent {
+	e := QueryEvent{Op: op, Query: query, Args: args, Start: time.Now()}
+	for _, hook := range h.hooks {
+		hook.Before(ctx, e)
+	}
+	return e
+}
+
+func (h *Hooked) after(ctx context.Context, e QueryEvent, err error) {
+	e.Duration = time.Since(e.Start)
+	e.Err = err
+	for _, hook := range h.hooks {
+		hook.After(ctx, e)
+	}
+}
@@ -0,0 +1,53 @@
+package xsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// In returns a placeholder list like "?,?,?" for values and the values as args,
+// to compose an IN clause piece by piece.
+// For an empty slice it returns an empty string and nil args, and leaves it
+// to the caller to decide what an empty IN means.
+//
+// Example:
+//
+//	placeholders, args := In([]int64{1, 2, 3})
+//	query := "SELECT id, name FROM users WHERE id IN (" + placeholders + ")"
+func In[T any](values []T) (placeholders string, args []any) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	return strings.Repeat("?,", len(values)-1) + "?", toArgs(values)
+}
+
+// InN is like In, but returns Postgres placeholders numbered from startIndex,
+// like "$3,$4,$5" for a startIndex of 3.
+//
+// Example:
+//
+//	placeholders, args := InN(2, ids)
+//	query := "SELECT id FROM users WHERE status = $1 AND id IN (" + placeholders + ")"
+//	args = append([]any{status}, args...)
+func InN[T any](startIndex int, values []T) (placeholders string, args []any) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	for i := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(startIndex + i))
+	}
+	return b.String(), toArgs(values)
+}
+
+func toArgs[T any](values []T) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,96 @@
+package xsql
+
+import (
+	"context"
+	"strings"
+)
+
+// InsertIgnore inserts rows into table, skipping the ones which conflict with an
+// existing row on a unique key, and reports how many rows were inserted and skipped.
+// It suits idempotent ingestion, where a sync job wants to know how much new data arrived.
+// Skipped rows are the ones the database didn't report as affected.
+//
+// The columns come from the fields of T like for SaveAll. For Postgres the statement
+// is INSERT ... ON CONFLICT DO NOTHING. For MySQL it is INSERT IGNORE, which also
+// turns other errors, e.g. values too long for their column, into warnings and
+// skips or truncates those rows, so they are counted as skipped or inserted.
+//
+// Rows are sent in as few statements as the placeholder limit allows. Each
+// statement commits on its own unless db is a transaction, and on error the counts
+// of the statements which completed are returned along with it.
+// Empty rows is a no-op.
+//
+// Example:
+//
+//	inserted, skipped, err := InsertIgnore(ctx, db, Postgres, "events", events)
+//	log.Printf("%d new events, %d already known", inserted, skipped)
+func InsertIgnore[T any](
+	ctx context.Context,
+	db DBTX,
+	dialect Dialect,
+	table string,
+	rows []T,
+) (inserted, skipped int64, err error) {
+	stmts, err := InsertIgnoreBatch[T]{Table: table, Rows: rows}.Statements(dialect)
+	if err != nil || len(stmts) == 0 {
+		return 0, 0, err
+	}
+	cols, _, err := batchColumns[T]()
+	if err != nil {
+		return 0, 0, err
+	}
+	// The statements hold the rows in order, as many as the placeholder
+	// limit allows, so only the last one may hold fewer.
+	perStmt := int64(maxPlaceholders / len(cols))
+
+	left := int64(len(rows))
+	for _, stmt := range stmts {
+		n := min(left, perStmt)
+		left -= n
+		res, err := db.ExecContext(ctx, stmt.Query, stmt.Args...)
+		PutArgs(stmt.Args)
+		if err != nil {
+			return inserted, skipped, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return inserted, skipped, err
+		}
+		inserted += affected
+		skipped += n - affected
+	}
+	return inserted, skipped, nil
+}
+
+// InsertIgnoreBatch is the Builder of the statements InsertIgnore runs.
+//
+// Example:
+//
+//	stmts, err := DryRun(MySQL, InsertIgnoreBatch[Event]{Table: "events", Rows: events})
+type InsertIgnoreBatch[T any] struct {
+	Table string
+	Rows  []T
+}
+
+// Statements implements Builder.
+func (b InsertIgnoreBatch[T]) Statements(dialect Dialect) ([]Statement, error) {
+	if len(b.Rows) == 0 {
+		return nil, nil
+	}
+	cols, index, err := batchColumns[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := insertPrefix(dialect, b.Table, cols)
+	if err != nil {
+		return nil, err
+	}
+	var suffix string
+	if dialect == Postgres {
+		suffix = " ON CONFLICT DO NOTHING"
+	} else {
+		prefix = "INSERT IGNORE" + strings.TrimPrefix(prefix, "INSERT")
+	}
+	return batchStatements(dialect, b.Rows, index, prefix, suffix), nil
+}
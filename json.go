@@ -0,0 +1,40 @@
+package xsql
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// QueryJSON runs a query returning a single JSON column, e.g. built with
+// Postgres json_agg or jsonb_build_object, and unmarshals it into a T.
+// This lets the database assemble nested results.
+//
+// It returns ErrNotFound if the query returned no row
+// and ErrNullResult if the JSON value is NULL.
+//
+// Example:
+//
+//	type Order struct {
+//		ID    int64  `json:"id"`
+//		Items []Item `json:"items"`
+//	}
+//	order, err := QueryJSON[Order](ctx, db, `
+//		SELECT jsonb_build_object('id', o.id, 'items', jsonb_agg(i))
+//		FROM orders o JOIN items i ON i.order_id = o.id
+//		WHERE o.id = $1
+//		GROUP BY o.id`, 1)
+func QueryJSON[T any](ctx context.Context, db DBTX, query string, args ...any) (T, error) {
+	return QueryOne(ctx, db, scanJSON[T], query, args...)
+}
+
+func scanJSON[T any](s Scanner) (v T, err error) {
+	var b []byte
+	if err := s.Scan(&b); err != nil {
+		return v, err
+	}
+	if b == nil {
+		return v, ErrNullResult
+	}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
@@ -0,0 +1,57 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/freakshake/xerror"
+)
+
+// WithOrderedLocks runs the lock statements, e.g. SELECT ... FOR UPDATE, one after
+// the other in the given order inside tx, then runs fn. Locks are held until tx ends.
+//
+// Deadlocks are avoided by acquiring locks in the same canonical order everywhere.
+// Choosing that order is the caller's responsibility: every call locking the same
+// tables or rows must pass its statements in the same order.
+//
+// A failing lock statement aborts the call with an error naming it; fn is not run.
+//
+// Example:
+//
+//	err := WithOrderedLocks(ctx, tx, []string{
+//		"SELECT id FROM accounts WHERE id = 1 FOR UPDATE",
+//		"SELECT id FROM accounts WHERE id = 2 FOR UPDATE",
+//	}, func() error {
+//		return transfer(ctx, tx, 1, 2, amount)
+//	})
+func WithOrderedLocks(
+	ctx context.Context,
+	tx *sql.Tx,
+	lockStatements []string,
+	fn func() error,
+) error {
+	for i, stmt := range lockStatements {
+		if err := lock(ctx, tx, stmt); err != nil {
+			xerror.Wrap(&err, "lock statement %d %q", i, stmt)
+			return err
+		}
+	}
+	return fn()
+}
+
+func lock(ctx context.Context, tx *sql.Tx, stmt string) (err error) {
+	rows, err := tx.QueryContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	for rows.Next() {
+	}
+	return rows.Err()
+}
@@ -0,0 +1,61 @@
+package xsql
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger returns a Hook logging every statement with l, so each wrapped DBTX
+// can log to its own handler. The start and end of statements are logged at
+// debug level, failed statements at error level, with the operation, query,
+// duration and error as attributes. A nil l logs nothing.
+//
+// Records filtered out by the level of l are not built, so a disabled logger
+// costs no allocation.
+//
+// Example:
+//
+//	db := WithHooks(sqlDB, Logger(slog.Default().With("component", "billing")))
+func Logger(l *slog.Logger) Hook {
+	return logHook{l: l}
+}
+
+type logHook struct {
+	l *slog.Logger
+}
+
+func (h logHook) Before(ctx context.Context, e QueryEvent) {
+	if h.l == nil || !h.l.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	h.l.LogAttrs(ctx, slog.LevelDebug, "xsql: query start",
+		slog.String("operation", e.Op),
+		slog.String("query", e.Query),
+	)
+}
+
+func (h logHook) After(ctx context.Context, e QueryEvent) {
+	if h.l == nil {
+		return
+	}
+	if e.Err != nil {
+		if !h.l.Enabled(ctx, slog.LevelError) {
+			return
+		}
+		h.l.LogAttrs(ctx, slog.LevelError, "xsql: query failed",
+			slog.String("operation", e.Op),
+			slog.String("query", e.Query),
+			slog.Duration("duration", e.Duration),
+			slog.Any("error", e.Err),
+		)
+		return
+	}
+	if !h.l.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	h.l.LogAttrs(ctx, slog.LevelDebug, "xsql: query end",
+		slog.String("operation", e.Op),
+		slog.String("query", e.Query),
+		slog.Duration("duration", e.Duration),
+	)
+}
@@ -0,0 +1,97 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/freakshake/xerror"
+)
+
+// NullAsNaN makes QueryMatrix store NULL values as NaN instead of failing.
+func NullAsNaN() Option {
+	return optionFunc(func(o *options) {
+		o.nullAsNaN = true
+	})
+}
+
+// QueryMatrix runs a query whose columns are all numeric and returns the result
+// as a row-major float64 matrix along with the column names, ready for numeric code.
+//
+// Integer, float and numeric text values, like DECIMAL columns returned as []byte,
+// are converted. Any other value fails with an error naming its column.
+// NULL values fail too, unless the NullAsNaN option is passed among the args.
+//
+// Example:
+//
+//	m, cols, err := QueryMatrix(ctx, db, "SELECT height, weight FROM patients", NullAsNaN())
+func QueryMatrix(ctx context.Context, db DBTX, query string, args ...any) (_ [][]float64, _ []string, err error) {
+	opts, args := splitOptions(args)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range dest {
+		dest[i] = &values[i]
+	}
+
+	var matrix [][]float64
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		row := make([]float64, len(cols))
+		for i, v := range values {
+			f, err := toFloat64(v, opts.nullAsNaN)
+			if err != nil {
+				return nil, nil, fmt.Errorf("xsql: QueryMatrix: column %q: %w", cols[i], err)
+			}
+			row[i] = f
+		}
+		matrix = append(matrix, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return matrix, cols, nil
+}
+
+func toFloat64(v any, nullAsNaN bool) (float64, error) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), nil
+	// go-sql-driver/mysql returns unsigned BIGINT as uint64.
+	case uint64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case []byte:
+		return strconv.ParseFloat(string(v), 64)
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		if nullAsNaN {
+			return math.NaN(), nil
+		}
+		return 0, fmt.Errorf("NULL value, pass NullAsNaN to allow it")
+	}
+	return 0, fmt.Errorf("%T value is not numeric", v)
+}
@@ -0,0 +1,36 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestQueryMatrix(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().
+		on("SELECT values", []string{"int", "uint", "float", "text"},
+			[]driver.Value{int64(-1), uint64(math.MaxUint64), 1.5, []byte("2.5")}).
+		on("SELECT null", []string{"n"}, []driver.Value{nil}).
+		open(t)
+
+	m, cols, err := QueryMatrix(ctx, db, "SELECT values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]float64{{-1, math.MaxUint64, 1.5, 2.5}}; !reflect.DeepEqual(m, want) {
+		t.Errorf("QueryMatrix = %v, want %v", m, want)
+	}
+	if want := []string{"int", "uint", "float", "text"}; !reflect.DeepEqual(cols, want) {
+		t.Errorf("QueryMatrix columns = %q, want %q", cols, want)
+	}
+
+	if _, _, err := QueryMatrix(ctx, db, "SELECT null"); err == nil {
+		t.Error("QueryMatrix accepted a NULL without NullAsNaN")
+	}
+	if m, _, err := QueryMatrix(ctx, db, "SELECT null", NullAsNaN()); err != nil || !math.IsNaN(m[0][0]) {
+		t.Errorf("QueryMatrix with NullAsNaN = %v, %v, want NaN", m, err)
+	}
+}
@@ -0,0 +1,52 @@
+package mysql
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// WithConnectionAttributes returns dsn with the connectionAttributes parameter
+// of github.com/go-sql-driver/mysql (v1.8 or later) set to attrs.
+// The attributes, e.g. program_name, are sent once per physical connection
+// during the handshake and show up in performance_schema.session_connect_attrs.
+//
+// Keys and values can't contain ',' or ':', which the driver uses as separators.
+// Other characters are escaped, so e.g. '&' doesn't end the parameter.
+//
+// Example:
+//
+//	dsn, err := mysql.WithConnectionAttributes("user:pass@tcp(127.0.0.1:3306)/mydb", map[string]string{
+//		"program_name": "billing-api",
+//	})
+//	if err != nil {
+//		panic(err)
+//	}
+//	db, err := sql.Open("mysql", dsn)
+func WithConnectionAttributes(dsn string, attrs map[string]string) (string, error) {
+	if len(attrs) == 0 {
+		return dsn, nil
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		v := attrs[k]
+		if strings.ContainsAny(k, ",:") || strings.ContainsAny(v, ",:") {
+			return "", errors.New("mysql: connection attribute " + k + " contains ',' or ':'")
+		}
+		pairs[i] = url.QueryEscape(k) + ":" + url.QueryEscape(v)
+	}
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "connectionAttributes=" + strings.Join(pairs, ","), nil
+}
@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWithConnectionAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		dsn   string
+		attrs map[string]string
+		want  string
+	}{
+		{"no attributes", "app@tcp(db:3306)/app", nil, "app@tcp(db:3306)/app"},
+		{
+			"sorted", "app@tcp(db:3306)/app",
+			map[string]string{"program_name": "billing", "env": "prod"},
+			"app@tcp(db:3306)/app?connectionAttributes=env:prod,program_name:billing",
+		},
+		{
+			"existing params", "app@tcp(db:3306)/app?parseTime=true",
+			map[string]string{"program_name": "billing"},
+			"app@tcp(db:3306)/app?parseTime=true&connectionAttributes=program_name:billing",
+		},
+		{
+			"escaped", "app@tcp(db:3306)/app",
+			map[string]string{"team": "a&b=c d"},
+			"app@tcp(db:3306)/app?connectionAttributes=team:a%26b%3Dc+d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithConnectionAttributes(tt.dsn, tt.attrs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("WithConnectionAttributes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The driver reads the parameter back with url.QueryUnescape.
+	dsn, _ := WithConnectionAttributes("app@tcp(db:3306)/app?parseTime=true", map[string]string{"team": "a&b"})
+	_, query, _ := strings.Cut(dsn, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params.Get("connectionAttributes"); got != "team:a&b" || params.Get("parseTime") != "true" {
+		t.Errorf("params = %v, want connectionAttributes team:a&b next to parseTime", params)
+	}
+}
+
+func TestWithConnectionAttributesSeparators(t *testing.T) {
+	for _, attrs := range []map[string]string{{"a,b": "c"}, {"a": "b:c"}} {
+		if _, err := WithConnectionAttributes("app@tcp(db:3306)/app", attrs); err == nil {
+			t.Errorf("WithConnectionAttributes(%v) succeeded, want an error", attrs)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package mysql contains helpers which only work with MySQL.
+// It is kept apart from package xsql so users of other databases
+// aren't affected by MySQL specifics.
+package mysql
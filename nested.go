@@ -0,0 +1,158 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/freakshake/xerror"
+)
+
+// ChildKey makes QueryNested skip the child of the rows whose column col,
+// counted from 0, is NULL. With a LEFT JOIN, pass the key column of the child
+// table: it is NULL on the row of a parent without children, which then gets
+// no children instead of failing in scanChild.
+//
+// Example:
+//
+//	orders, err := QueryNested(ctx, db, orderID, scanOrder, scanItem,
+//		"SELECT o.id, o.total, i.sku, i.qty FROM orders o LEFT JOIN items i ON i.order_id = o.id",
+//		ChildKey(2),
+//	)
+func ChildKey(col int) Option {
+	return optionFunc(func(o *options) {
+		o.childKey = col
+	})
+}
+
+// ParentWith is a parent row together with its child rows, as returned by QueryNested.
+type ParentWith[P, C any] struct {
+	Parent   P
+	Children []C
+}
+
+// QueryNested assembles the rows of a one-to-many join into parents holding
+// their children, without issuing a query per parent.
+//
+// For every row parentKey extracts the key identifying the parent. scanParent is
+// called on the first row of each parent only, scanChild on every row.
+// Parents are returned in order of first appearance.
+//
+// All three functions get the same row, and since Scan needs a destination
+// for every column each of them must scan all columns. Columns a function is
+// not interested in can be scanned into a throw-away *any.
+//
+// For a LEFT JOIN, pass the ChildKey option among the args, so parents without
+// children don't get a child scanned from NULL columns.
+//
+// Example:
+//
+//	const query = "SELECT o.id, o.total, i.sku, i.qty FROM orders o JOIN items i ON i.order_id = o.id"
+//	var skip any
+//	orders, err := QueryNested(ctx, db,
+//		func(s Scanner) (id int64, err error) {
+//			return id, s.Scan(&id, &skip, &skip, &skip)
+//		},
+//		func(s Scanner) (o Order, err error) {
+//			return o, s.Scan(&o.ID, &o.Total, &skip, &skip)
+//		},
+//		func(s Scanner) (i Item, err error) {
+//			return i, s.Scan(&skip, &skip, &i.SKU, &i.Qty)
+//		},
+//		query,
+//	)
+func QueryNested[K comparable, P, C any](
+	ctx context.Context,
+	db DBTX,
+	parentKey func(Scanner) (K, error),
+	scanParent func(Scanner) (P, error),
+	scanChild func(Scanner) (C, error),
+	query string,
+	args ...any,
+) (_ []ParentWith[P, C], err error) {
+	o, args := splitOptions(args)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	// childKey scans the rows when checking their child key column.
+	var childKey *nullChecker
+	if o.childKey >= 0 {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		if o.childKey >= len(cols) {
+			return nil, fmt.Errorf("xsql: QueryNested: child key column %d out of %d columns", o.childKey, len(cols))
+		}
+		childKey = newNullChecker(len(cols), o.childKey)
+	}
+
+	var results []ParentWith[P, C]
+	index := make(map[K]int)
+
+	for rows.Next() {
+		key, err := parentKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[key]
+		if !ok {
+			parent, err := scanParent(rows)
+			if err != nil {
+				return nil, err
+			}
+			i = len(results)
+			index[key] = i
+			results = append(results, ParentWith[P, C]{Parent: parent})
+		}
+		if childKey != nil {
+			null, err := childKey.isNull(rows)
+			if err != nil {
+				return nil, err
+			}
+			if null {
+				continue
+			}
+		}
+		child, err := scanChild(rows)
+		if err != nil {
+			return nil, err
+		}
+		results[i].Children = append(results[i].Children, child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// nullChecker tells whether a column of the current row is NULL.
+type nullChecker struct {
+	col    int
+	values []any
+	dest   []any
+}
+
+func newNullChecker(n, col int) *nullChecker {
+	c := &nullChecker{col: col, values: make([]any, n), dest: make([]any, n)}
+	for i := range c.values {
+		c.dest[i] = &c.values[i]
+	}
+	return c
+}
+
+// isNull reports whether the column of the current row of s is NULL.
+func (c *nullChecker) isNull(s Scanner) (bool, error) {
+	if err := s.Scan(c.dest...); err != nil {
+		return false, err
+	}
+	return c.values[c.col] == nil, nil
+}
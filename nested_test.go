@@ -0,0 +1,42 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"testing"
+)
+
+func TestQueryNestedLeftJoin(t *testing.T) {
+	ctx := context.Background()
+	const query = "SELECT o.id, i.sku FROM orders o LEFT JOIN items i ON i.order_id = o.id"
+	db := newFakeDB().
+		on(query, []string{"id", "sku"},
+			[]driver.Value{int64(1), "a"},
+			[]driver.Value{int64(2), nil},
+			[]driver.Value{int64(1), "b"}).
+		open(t)
+
+	var skip any
+	orderID := func(s Scanner) (id int64, err error) {
+		return id, s.Scan(&id, &skip)
+	}
+	sku := func(s Scanner) (sku string, err error) {
+		return sku, s.Scan(&skip, &sku)
+	}
+
+	got, err := QueryNested(ctx, db, orderID, orderID, sku, query, ChildKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Parent != 1 || !slices.Equal(got[0].Children, []string{"a", "b"}) || got[1].Parent != 2 || got[1].Children != nil {
+		t.Errorf("QueryNested = %+v, want order 1 with a and b, order 2 without children", got)
+	}
+
+	if _, err := QueryNested(ctx, db, orderID, orderID, sku, query); err == nil {
+		t.Error("QueryNested scanned a NULL child without ChildKey")
+	}
+	if _, err := QueryNested(ctx, db, orderID, orderID, sku, query, ChildKey(2)); err == nil {
+		t.Error("QueryNested accepted a child key column out of range")
+	}
+}
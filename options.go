@@ -0,0 +1,124 @@
+package xsql
+
+import (
+	"fmt"
+)
+
+// Option changes the behaviour of a single QueryOne or QueryMany call,
+// or of the other helpers documenting an option.
+//
+// Options are passed among the query arguments and are removed
+// before the arguments reach the driver.
+//
+// Example:
+//
+//	users, err := QueryMany(ctx, db, scanUser, "SELECT id, name FROM users WHERE age = ?", 34, Take(10))
+type Option interface {
+	apply(*options)
+}
+
+type options struct {
+	// take is the maximum number of rows to scan, -1 means all of them.
+	take int
+	// comment is appended to the query, see WithComment.
+	comment string
+	// mapErr translates the returned error, see MapErrors.
+	mapErr ErrorMapper
+	// drain reads the remaining rows after a scan error, see DrainOnError.
+	drain bool
+	// nullAsNaN stores NULL as NaN in QueryMatrix, see NullAsNaN.
+	nullAsNaN bool
+	// validate checks every scanned row, see Validate.
+	validate func(any) error
+	// concurrency runs the queries of QueryManyUnion in parallel, see Concurrent.
+	// 0 runs them one after the other, -1 all at once.
+	concurrency int
+	// childKey is the column whose NULL makes QueryNested skip the child,
+	// see ChildKey. -1 means none.
+	childKey int
+}
+
+type optionFunc func(*options)
+
+func (f optionFunc) apply(o *options) {
+	f(o)
+}
+
+// Take makes QueryMany scan at most n rows and silently drop the rest.
+// It is meant for queries whose SQL can't be changed to add a LIMIT.
+// Rows are closed as soon as n rows were scanned, which releases the connection cleanly.
+func Take(n int) Option {
+	return optionFunc(func(o *options) {
+		o.take = n
+	})
+}
+
+// DrainOnError makes QueryMany read the remaining rows to exhaustion
+// before closing them when scanning or validating a row fails midway.
+//
+// Some drivers can only reuse a connection whose result set was fully read
+// and otherwise discard it or pay a round trip to cancel the query. Draining
+// keeps the pooled connection healthy on those drivers, at the cost of
+// transferring the rest of the result, which can be large. Most drivers,
+// e.g. lib/pq and go-sql-driver/mysql, discard unread rows on Close by
+// themselves and don't need it.
+func DrainOnError() Option {
+	return optionFunc(func(o *options) {
+		o.drain = true
+	})
+}
+
+// Validate makes QueryMany run fn on every successfully scanned row, before
+// filtering with QueryManyFilter. An error returned by fn aborts the query and
+// is returned, so bad data is caught at the boundary, e.g. a negative balance.
+// Combined with DrainOnError the remaining rows are drained as for scan errors.
+//
+// T must be the row type of the query, otherwise QueryMany fails.
+//
+// Example:
+//
+//	accounts, err := QueryMany(ctx, db, scanAccount, "SELECT id, balance FROM accounts",
+//		Validate(func(a Account) error {
+//			if a.Balance < 0 {
+//				return fmt.Errorf("account %d: negative balance", a.ID)
+//			}
+//			return nil
+//		}),
+//	)
+func Validate[T any](fn func(T) error) Option {
+	return optionFunc(func(o *options) {
+		o.validate = func(v any) error {
+			t, ok := v.(T)
+			if !ok {
+				return fmt.Errorf("xsql: Validate option for %T used with rows of type %T", t, v)
+			}
+			return fn(t)
+		}
+	})
+}
+
+// splitOptions separates the options from the query arguments.
+// args is returned as is when it holds no option.
+func splitOptions(args []any) (options, []any) {
+	o := options{take: -1, childKey: -1}
+
+	n := 0
+	for _, arg := range args {
+		if _, ok := arg.(Option); ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return o, args
+	}
+
+	queryArgs := make([]any, 0, len(args)-n)
+	for _, arg := range args {
+		if opt, ok := arg.(Option); ok {
+			opt.apply(&o)
+			continue
+		}
+		queryArgs = append(queryArgs, arg)
+	}
+	return o, queryArgs
+}
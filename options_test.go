@@ -0,0 +1,85 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newCountingDB returns a fakeDB whose query "SELECT n" returns the numbers 1 to n.
+func newCountingDB(n int) *fakeDB {
+	rows := make([][]driver.Value, n)
+	for i := range rows {
+		rows[i] = []driver.Value{int64(i + 1)}
+	}
+	return newFakeDB().on("SELECT n", []string{"n"}, rows...)
+}
+
+func TestTake(t *testing.T) {
+	ctx := context.Background()
+	db := newCountingDB(100).open(t)
+
+	tests := []struct {
+		name string
+		args []any
+		want []int64
+	}{
+		{"fewer rows", []any{Take(3)}, []int64{1, 2, 3}},
+		{"among args", []any{50, Take(2)}, []int64{1, 2}},
+		{"zero", []any{Take(0)}, []int64{}},
+		{"more than available", []any{Take(200)}, nil},
+		{"no take", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryMany(ctx, db, ScanID[int64], "SELECT n", tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil && len(got) != 100 || tt.want != nil && !slices.Equal(got, tt.want) {
+				t.Errorf("QueryMany = %v, want %v", got, tt.want)
+			}
+			// Stopping early closed the rows and released the connection.
+			if n := db.Stats().InUse; n != 0 {
+				t.Errorf("%d connections in use", n)
+			}
+		})
+	}
+}
+
+func TestDrainOnError(t *testing.T) {
+	ctx := context.Background()
+	errScan := errors.New("scan failed")
+	// scan fails on the second row.
+	scan := func(s Scanner) (int64, error) {
+		n, err := ScanID[int64](s)
+		if err == nil && n == 2 {
+			err = errScan
+		}
+		return n, err
+	}
+
+	tests := []struct {
+		name string
+		opts []any
+		read int
+	}{
+		{"default", nil, 2},
+		{"drain", []any{DrainOnError()}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCountingDB(5)
+			db := f.open(t)
+
+			if _, err := QueryMany(ctx, db, scan, "SELECT n", tt.opts...); !errors.Is(err, errScan) {
+				t.Fatalf("QueryMany error = %v, want %v", err, errScan)
+			}
+			if got := f.rowsRead(); got != tt.read {
+				t.Errorf("%d rows read, want %d", got, tt.read)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package xsql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSort is returned by OrderBy for sort input it doesn't accept,
+// so handlers can answer it with a client error.
+var ErrInvalidSort = errors.New("xsql: invalid sort")
+
+// OrderBy turns the sort parameter of an API, a comma separated list of field
+// names each optionally prefixed with "-" for descending order, into an
+// ORDER BY clause. allowed maps the field names of the API to the columns
+// they sort by. Empty input returns an empty clause.
+//
+// A field missing from allowed, an empty field or a field given twice fails
+// with ErrInvalidSort rather than being dropped, so a client never gets an
+// order it didn't ask for. Only the columns of allowed end up in the clause,
+// they are written as is and may be qualified or expressions, e.g. "u.name".
+//
+// Example:
+//
+//	// ORDER BY u.name ASC, u.created_at DESC
+//	clause, err := OrderBy(r.URL.Query().Get("sort"), map[string]string{
+//		"name":       "u.name",
+//		"created_at": "u.created_at",
+//	})
+//	if errors.Is(err, ErrInvalidSort) {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+func OrderBy(input string, allowed map[string]string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", nil
+	}
+
+	fields := strings.Split(input, ",")
+	terms := make([]string, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		dir := " ASC"
+		if name, ok := strings.CutPrefix(field, "-"); ok {
+			field, dir = name, " DESC"
+		}
+		if field == "" {
+			return "", fmt.Errorf("%w: empty field in %q", ErrInvalidSort, input)
+		}
+		col, ok := allowed[field]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown field %q", ErrInvalidSort, field)
+		}
+		if seen[field] {
+			return "", fmt.Errorf("%w: field %q given twice", ErrInvalidSort, field)
+		}
+		seen[field] = true
+		terms[i] = col + dir
+	}
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,62 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrOverflow is wrapped by the OverflowError of QueryColumnChecked.
+var ErrOverflow = errors.New("xsql: integer overflow")
+
+// Integer is the set of Go integer types, like golang.org/x/exp/constraints.Integer.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// OverflowError is returned by QueryColumnChecked for a value which doesn't fit
+// the requested type. errors.Is(err, ErrOverflow) reports true for it.
+type OverflowError struct {
+	// Value is the offending value.
+	Value int64
+	// Type is the name of the requested type.
+	Type string
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("xsql: value %d overflows %s", e.Value, e.Type)
+}
+
+func (e *OverflowError) Unwrap() error {
+	return ErrOverflow
+}
+
+// QueryColumnChecked runs the query, which must select a single integer column,
+// and returns its values converted to T. Each value is scanned into an int64 and
+// range checked before the conversion, so a value which doesn't fit T fails with an
+// OverflowError instead of being truncated silently, e.g. a BIGINT read into int32.
+// NULL values fail the scan, select COALESCE(col, 0) to read them as 0.
+//
+// Example:
+//
+//	ports, err := QueryColumnChecked[uint16](ctx, db, "SELECT port FROM services")
+//	var overflow *OverflowError
+//	if errors.As(err, &overflow) {
+//		log.Printf("port %d out of range", overflow.Value)
+//	}
+func QueryColumnChecked[T Integer](ctx context.Context, db DBTX, query string, args ...any) ([]T, error) {
+	return queryMany(ctx, db, scanChecked[T], nil, query, args)
+}
+
+func scanChecked[T Integer](s Scanner) (T, error) {
+	var v int64
+	if err := s.Scan(&v); err != nil {
+		return 0, err
+	}
+	t := T(v)
+	if int64(t) != v || (v < 0) != (t < 0) {
+		return 0, &OverflowError{Value: v, Type: fmt.Sprintf("%T", t)}
+	}
+	return t, nil
+}
@@ -0,0 +1,69 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+type port uint16
+
+func TestQueryColumnChecked(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	tests := []struct {
+		name  string
+		value int64
+		check func(DBTX, int64) (int64, error)
+		typ   string // the type named by the overflow error, "" if the value fits
+	}{
+		{"int32 max", math.MaxInt32, checked[int32], ""},
+		{"int32 min", math.MinInt32, checked[int32], ""},
+		{"int32 above max", math.MaxInt32 + 1, checked[int32], "int32"},
+		{"int32 below min", math.MinInt32 - 1, checked[int32], "int32"},
+		{"uint16 max", math.MaxUint16, checked[uint16], ""},
+		{"uint16 zero", 0, checked[uint16], ""},
+		{"uint16 above max", math.MaxUint16 + 1, checked[uint16], "uint16"},
+		{"uint16 negative", -1, checked[uint16], "uint16"},
+		{"int8 min", math.MinInt8, checked[int8], ""},
+		{"int8 above max", math.MaxInt8 + 1, checked[int8], "int8"},
+		{"uint64 max int64", math.MaxInt64, checked[uint64], ""},
+		{"uint64 negative", -1, checked[uint64], "uint64"},
+		{"int64 min", math.MinInt64, checked[int64], ""},
+		{"named type", math.MaxUint16 + 1, checked[port], "xsql.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.check(db, tt.value)
+			if tt.typ == "" {
+				if err != nil || got != tt.value {
+					t.Errorf("QueryColumnChecked = %d, %v, want %d", got, err, tt.value)
+				}
+				return
+			}
+			var overflow *OverflowError
+			if !errors.As(err, &overflow) || !errors.Is(err, ErrOverflow) {
+				t.Fatalf("QueryColumnChecked error = %v, want an OverflowError", err)
+			}
+			if overflow.Value != tt.value || overflow.Type != tt.typ {
+				t.Errorf("OverflowError = %+v, want value %d and type %s", overflow, tt.value, tt.typ)
+			}
+		})
+	}
+
+	vs, err := QueryColumnChecked[uint16](ctx, db, "SELECT 1 UNION ALL SELECT 70000 UNION ALL SELECT 2")
+	if !errors.Is(err, ErrOverflow) || vs != nil {
+		t.Errorf("QueryColumnChecked = %v, %v, want an overflow for the second row", vs, err)
+	}
+}
+
+// checked selects v with QueryColumnChecked[T] on db.
+func checked[T Integer](db DBTX, v int64) (int64, error) {
+	vs, err := QueryColumnChecked[T](context.Background(), db, "SELECT ?", v)
+	if err != nil {
+		return 0, err
+	}
+	return int64(vs[0]), nil
+}
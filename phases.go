@@ -0,0 +1,84 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrAcquireTimeout is returned by WithTimeouts when no connection became free
+	// in time, a sign the pool is too small for the load.
+	ErrAcquireTimeout = errors.New("xsql: timeout acquiring connection")
+	// ErrExecTimeout is returned by WithTimeouts when the statements took too long
+	// once they had a connection, a sign the queries need optimizing.
+	ErrExecTimeout = errors.New("xsql: timeout executing")
+)
+
+// WithTimeouts runs fn on a connection of db with separate timeouts for getting
+// the connection from the pool and for running fn, and reports which of them expired.
+//
+// The connection is acquired with db.Conn within acquire, otherwise ErrAcquireTimeout
+// is returned. fn then runs on it with a context expiring after exec; if fn fails
+// once that context expired, the error wraps both ErrExecTimeout and fn's error.
+// A deadline or cancellation of ctx itself is returned as is, so callers can tell
+// it apart from the two timeouts.
+//
+// Acquiring the connection upfront costs a little over a plain query on db, and
+// the connection is held until fn returns, see WithConn.
+//
+// Example:
+//
+//	err := WithTimeouts(ctx, db, 100*time.Millisecond, 2*time.Second, func(ctx context.Context, conn *sql.Conn) error {
+//		users, err = QueryMany(ctx, conn, scanUser, "SELECT id, name FROM users")
+//		return err
+//	})
+//	switch {
+//	case errors.Is(err, ErrAcquireTimeout):
+//		// scale the pool
+//	case errors.Is(err, ErrExecTimeout):
+//		// optimize the query
+//	}
+func WithTimeouts(
+	ctx context.Context,
+	db *sql.DB,
+	acquire, exec time.Duration,
+	fn func(ctx context.Context, conn *sql.Conn) error,
+) error {
+	actx, cancel := context.WithTimeout(ctx, acquire)
+	conn, err := db.Conn(actx)
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrAcquireTimeout, acquire)
+		}
+		return err
+	}
+	return withConn(conn, func(conn *sql.Conn) error {
+		ectx, cancel := context.WithTimeout(ctx, exec)
+		defer cancel()
+		err := fn(ectx, conn)
+		if err != nil && ctx.Err() == nil && ectx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%w after %s: %w", ErrExecTimeout, exec, err)
+		}
+		return err
+	})
+}
+
+// QueryManyTimeouts is like QueryMany, but runs the query with WithTimeouts.
+func QueryManyTimeouts[T any](
+	ctx context.Context,
+	db *sql.DB,
+	acquire, exec time.Duration,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) (res []T, err error) {
+	err = WithTimeouts(ctx, db, acquire, exec, func(ctx context.Context, conn *sql.Conn) error {
+		res, err = QueryMany(ctx, conn, scan, query, args...)
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,70 @@
+package xsql
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// defaultMaxIdleConns is the database/sql default for MaxIdleConns.
+const defaultMaxIdleConns = 2
+
+// PoolConfig holds the connection pool settings of a sql.DB.
+type PoolConfig struct {
+	MaxOpen         int
+	MaxIdle         int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Pool is a sql.DB along with the pool settings applied to it by Open or
+// ApplyPool, since database/sql has no getters for most of them.
+// The sql.DB methods are promoted, so a Pool is a DBTX.
+//
+// A Pool is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	*sql.DB
+
+	mu     sync.Mutex
+	config PoolConfig
+}
+
+// NewPool returns a Pool for db, which was configured otherwise than by Open,
+// e.g. opened with sql.Open. Its settings are taken to be the database/sql
+// defaults until ApplyPool is called.
+func NewPool(db *sql.DB) *Pool {
+	return &Pool{DB: db, config: PoolConfig{MaxIdle: defaultMaxIdleConns}}
+}
+
+// SnapshotPool returns the current pool settings of p, so load tests can
+// change them and restore them afterwards with ApplyPool.
+//
+// database/sql only exposes MaxOpen, through db.Stats, which is always read from p.DB.
+// The other settings can only be set, so they are returned as last applied by
+// Open or ApplyPool. Settings changed on p.DB directly, e.g. by calling
+// p.DB.SetMaxIdleConns, are not seen.
+func SnapshotPool(p *Pool) PoolConfig {
+	p.mu.Lock()
+	c := p.config
+	p.mu.Unlock()
+	c.MaxOpen = p.DB.Stats().MaxOpenConnections
+	return c
+}
+
+// ApplyPool applies all the settings of c to p and remembers them for SnapshotPool.
+//
+// Example:
+//
+//	saved := SnapshotPool(db)
+//	defer ApplyPool(db, saved)
+//	ApplyPool(db, PoolConfig{MaxOpen: 5, MaxIdle: 5})
+//	runLoadTest(db)
+func ApplyPool(p *Pool, c PoolConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.DB.SetMaxOpenConns(c.MaxOpen)
+	p.DB.SetMaxIdleConns(c.MaxIdle)
+	p.DB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	p.DB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	p.config = c
+}
@@ -0,0 +1,40 @@
+package xsql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSnapshotPool(t *testing.T) {
+	db := NewPool(openTestDB(t))
+
+	if got, want := SnapshotPool(db), (PoolConfig{MaxOpen: 1, MaxIdle: defaultMaxIdleConns}); got != want {
+		t.Errorf("SnapshotPool of a new Pool = %+v, want %+v", got, want)
+	}
+
+	c := PoolConfig{MaxOpen: 5, MaxIdle: 3, ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Second}
+	ApplyPool(db, c)
+	if got := SnapshotPool(db); got != c {
+		t.Errorf("SnapshotPool after ApplyPool = %+v, want %+v", got, c)
+	}
+
+	// MaxOpen is always read from db.
+	db.SetMaxOpenConns(7)
+	if got := SnapshotPool(db).MaxOpen; got != 7 {
+		t.Errorf("SnapshotPool MaxOpen = %d, want 7", got)
+	}
+}
+
+func TestOpenSnapshotPool(t *testing.T) {
+	db, err := Open(context.Background(), "xsql-ping", "ok", WithMaxOpen(4), WithConnMaxLifetime(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	want := PoolConfig{MaxOpen: 4, MaxIdle: defaultMaxIdleConns, ConnMaxLifetime: time.Minute}
+	if got := SnapshotPool(db); got != want {
+		t.Errorf("SnapshotPool after Open = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/freakshake/xerror"
+	"github.com/freakshake/xsql"
+)
+
+// WithAdvisoryLock runs fn while holding the session level advisory lock key,
+// waiting for it with pg_advisory_lock if another session holds it.
+// It is a simple primitive for singleton jobs and leader election across processes.
+//
+// The lock is taken on a dedicated connection, see xsql.WithConn, and released
+// with pg_advisory_unlock once fn returns, even if it panics. The lock is tied to
+// the lifetime of that connection: if the connection breaks while fn runs, the server
+// releases the lock and another process may take it, and if the unlock fails the
+// connection is discarded so the lock can't outlive fn.
+// fn's own queries run through db as usual, on other connections.
+//
+// Example:
+//
+//	err := postgres.WithAdvisoryLock(ctx, db, jobKey, func() error {
+//		return runNightlyReport(ctx, db)
+//	})
+func WithAdvisoryLock(ctx context.Context, db *sql.DB, key int64, fn func() error) error {
+	return xsql.WithConn(ctx, db, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			xerror.Wrap(&err, "pg_advisory_lock(%d)", key)
+			return err
+		}
+		defer unlockAdvisory(ctx, conn, key)
+		return fn()
+	})
+}
+
+// TryAdvisoryLock is like WithAdvisoryLock, but doesn't wait for the lock.
+// It takes it with pg_try_advisory_lock and returns false without running fn
+// if another session holds it.
+//
+// Example:
+//
+//	ran, err := postgres.TryAdvisoryLock(ctx, db, jobKey, func() error {
+//		return runNightlyReport(ctx, db)
+//	})
+//	if err == nil && !ran {
+//		log.Print("report already running elsewhere")
+//	}
+func TryAdvisoryLock(ctx context.Context, db *sql.DB, key int64, fn func() error) (locked bool, err error) {
+	err = xsql.WithConn(ctx, db, func(conn *sql.Conn) error {
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			xerror.Wrap(&err, "pg_try_advisory_lock(%d)", key)
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		defer unlockAdvisory(ctx, conn, key)
+		return fn()
+	})
+	return locked, err
+}
+
+// unlockAdvisory releases the advisory lock key held by conn,
+// or discards conn when that fails.
+func unlockAdvisory(ctx context.Context, conn *sql.Conn, key int64) {
+	// Unlock even when ctx is cancelled, the connection goes back to the pool.
+	var unlocked bool
+	err := conn.QueryRowContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key).Scan(&unlocked)
+	if err != nil || !unlocked {
+		_ = conn.Raw(func(any) error {
+			return driver.ErrBadConn
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/freakshake/xsql"
+	"github.com/lib/pq"
+)
+
+// OpenWithApplicationName opens dsn with lib/pq and sets application_name
+// on every connection, so the service shows up in pg_stat_activity and the
+// server logs.
+//
+// The setting is applied once per physical connection, when the pool opens it,
+// see xsql.NewInitConnector.
+//
+// Example:
+//
+//	db, err := postgres.OpenWithApplicationName("postgres://localhost/mydb", "billing-api")
+func OpenWithApplicationName(dsn, name string) (*sql.DB, error) {
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(xsql.NewInitConnector(c, "SET application_name = "+quoteLiteral(name))), nil
+}
+
+// quoteLiteral quotes s as a Postgres string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/freakshake/xsql"
+)
+
+// ErrCostExceeded is returned by a CostGuard refusing a query.
+var ErrCostExceeded = errors.New("postgres: estimated query cost exceeded")
+
+// CostLimitEnv is the environment variable read by CostGuardFromEnv.
+const CostLimitEnv = "XSQL_COST_LIMIT"
+
+// CostGuard is a xsql.DBTX refusing SELECT queries the planner estimates to cost
+// more than a limit, catching accidental cartesian products or full scans in CI or
+// staging before they hammer the database. Create it with NewCostGuard or CostGuardFromEnv.
+//
+// Every SELECT is first run with EXPLAIN (FORMAT JSON), which plans it without
+// executing it, and refused if the Total Cost of the top plan node exceeds the limit.
+// The error wraps ErrCostExceeded and holds the estimated cost. Other statements
+// are passed on unchecked. Planning every query twice costs a round trip per query,
+// so CostGuard is not meant for production.
+//
+// CostGuard implements xsql.RowErrQuerier, so xsql.QueryOne and xsql.Row
+// return ErrCostExceeded for a refused query too. A sql.Row can't carry an
+// arbitrary error, so its QueryRowContext, when called directly, can only report
+// a refused query as context.Canceled.
+type CostGuard struct {
+	db    xsql.DBTX
+	limit float64
+}
+
+// NewCostGuard returns a CostGuard refusing SELECTs with an estimated cost above limit.
+func NewCostGuard(db xsql.DBTX, limit float64) *CostGuard {
+	return &CostGuard{db: db, limit: limit}
+}
+
+// CostGuardFromEnv wraps db in a CostGuard if the CostLimitEnv environment variable
+// holds a limit, and returns db as is when it is unset, so the guard can be enabled
+// per environment without code changes.
+//
+// Example:
+//
+//	// XSQL_COST_LIMIT=100000 in CI and staging
+//	db, err := postgres.CostGuardFromEnv(sqlDB)
+//	if err != nil {
+//		panic(err)
+//	}
+func CostGuardFromEnv(db xsql.DBTX) (xsql.DBTX, error) {
+	v := os.Getenv(CostLimitEnv)
+	if v == "" {
+		return db, nil
+	}
+	limit, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %s: %w", CostLimitEnv, err)
+	}
+	return NewCostGuard(db, limit), nil
+}
+
+// ExecContext executes the statement on the wrapped DBTX without checking it.
+func (g *CostGuard) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return g.db.ExecContext(ctx, query, args...)
+}
+
+// QueryContext checks the estimated cost of SELECTs and runs the query on the wrapped DBTX.
+func (g *CostGuard) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := g.check(ctx, query, args); err != nil {
+		return nil, err
+	}
+	return g.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext checks the estimated cost of SELECTs and runs the query on the wrapped DBTX.
+// A refused query fails with context.Canceled, see QueryRowErrContext. When the
+// EXPLAIN itself fails the query is run anyway, so the Row reports its real error.
+func (g *CostGuard) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if err := g.check(ctx, query, args); errors.Is(err, ErrCostExceeded) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		ctx = cctx
+	}
+	return g.db.QueryRowContext(ctx, query, args...)
+}
+
+// QueryRowErrContext implements xsql.RowErrQuerier. It checks the estimated cost
+// of SELECTs like QueryContext, returning the error of a refusal or of the EXPLAIN,
+// and runs the query on the wrapped DBTX.
+func (g *CostGuard) QueryRowErrContext(ctx context.Context, query string, args ...any) (*sql.Row, error) {
+	if err := g.check(ctx, query, args); err != nil {
+		return nil, err
+	}
+	return g.db.QueryRowContext(ctx, query, args...), nil
+}
+
+// check returns an error if query is a SELECT estimated to cost more than the limit.
+func (g *CostGuard) check(ctx context.Context, query string, args []any) error {
+	if !isSelect(query) {
+		return nil
+	}
+
+	var out []byte
+	if err := g.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&out); err != nil {
+		return fmt.Errorf("postgres: explain query: %w", err)
+	}
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(out, &plans); err != nil {
+		return fmt.Errorf("postgres: parse query plan: %w", err)
+	}
+	for _, p := range plans {
+		if p.Plan.TotalCost > g.limit {
+			return fmt.Errorf("%w: estimated cost %.2f, limit %.2f", ErrCostExceeded, p.Plan.TotalCost, g.limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,4 @@
+// Package postgres contains helpers which only work with PostgreSQL.
+// It is kept apart from package xsql so users of other databases
+// don't depend on Postgres drivers.
+package postgres
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = 100 * time.Millisecond
+	maxReconnectInterval = time.Minute
+	pingInterval         = 90 * time.Second
+)
+
+// Notification is a message received by a Listener.
+type Notification struct {
+	Channel string
+	Payload string
+	// Reconnected is set on the synthetic notification delivered after the
+	// connection has been re-established. Notifications sent while the
+	// Listener was disconnected are lost, so the handler must treat it as
+	// "anything may have changed", e.g. by invalidating the whole cache.
+	Reconnected bool
+}
+
+// Listener subscribes to a Postgres channel with LISTEN and calls a handler
+// for every NOTIFY message. It is built on the lib/pq Listener.
+//
+// When the connection is lost the Listener reconnects with exponential backoff,
+// from 100ms up to one minute. Connection errors are reported on Errors.
+//
+// Delivery is at-most-once: every notification received is delivered once, but
+// the ones sent while the Listener was disconnected are lost. After a reconnect
+// the handler is called with Reconnected set instead, so it can resynchronize.
+//
+// Example:
+//
+//	l, err := postgres.NewListener(dsn, "cache_invalidation", func(n postgres.Notification) {
+//		if n.Reconnected {
+//			cache.Clear()
+//			return
+//		}
+//		cache.Invalidate(n.Payload)
+//	})
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer l.Close()
+type Listener struct {
+	l      *pq.Listener
+	errs   chan error
+	done   chan struct{}
+	closer sync.Once
+	// pinging is set while a keepalive ping is outstanding.
+	pinging atomic.Bool
+}
+
+// NewListener connects to dsn, subscribes to channel and starts calling
+// handle for its notifications. handle is called from a single goroutine,
+// a slow handler delays the following notifications.
+func NewListener(dsn, channel string, handle func(Notification)) (*Listener, error) {
+	l := &Listener{
+		errs: make(chan error, 16),
+		done: make(chan struct{}),
+	}
+	l.l = pq.NewListener(dsn, minReconnectInterval, maxReconnectInterval, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			l.report(err)
+		}
+	})
+	if err := l.l.Listen(channel); err != nil {
+		_ = l.l.Close()
+		return nil, err
+	}
+
+	go l.run(channel, handle)
+
+	return l, nil
+}
+
+// Errors returns the channel on which connection errors are reported.
+// Errors are dropped when the channel is full, so it is fine not to read it.
+func (l *Listener) Errors() <-chan error {
+	return l.errs
+}
+
+// Close stops the Listener and closes its connection.
+func (l *Listener) Close() error {
+	err := errors.New("postgres: listener already closed")
+	l.closer.Do(func() {
+		close(l.done)
+		err = l.l.Close()
+	})
+	return err
+}
+
+func (l *Listener) run(channel string, handle func(Notification)) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case n, ok := <-l.l.NotificationChannel():
+			// pq closes the channel on Close, which may win the select over done.
+			if !ok {
+				return
+			}
+			// pq sends nil after a reconnect.
+			if n == nil {
+				handle(Notification{Channel: channel, Reconnected: true})
+				continue
+			}
+			handle(Notification{Channel: n.Channel, Payload: n.Extra})
+		case <-ticker.C:
+			// Detect dead connections when no notification arrives for a while.
+			// Ping can't be cancelled, so while one hangs on a stuck connection
+			// no other is started.
+			if !l.pinging.CompareAndSwap(false, true) {
+				continue
+			}
+			go func() {
+				defer l.pinging.Store(false)
+				if err := l.l.Ping(); err != nil {
+					l.report(err)
+				}
+			}()
+		}
+	}
+}
+
+func (l *Listener) report(err error) {
+	select {
+	case l.errs <- err:
+	default:
+	}
+}
@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/freakshake/xsql"
+)
+
+// ExecReturningIDs executes a bulk UPDATE or DELETE ending in RETURNING id
+// and returns the ids of the affected rows, saving a follow-up SELECT.
+// When no row was affected it returns an empty slice, not an error.
+//
+// Example:
+//
+//	ids, err := postgres.ExecReturningIDs[int64](ctx, db,
+//		"UPDATE orders SET status = 'expired' WHERE created_at < $1 RETURNING id", cutoff)
+func ExecReturningIDs[T any](ctx context.Context, db xsql.DBTX, query string, args ...any) ([]T, error) {
+	return xsql.QueryMany(ctx, db, xsql.ScanID[T], query, args...)
+}
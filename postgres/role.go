@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/freakshake/xerror"
+	"github.com/freakshake/xsql"
+)
+
+// WithRole runs fn inside a transaction in which the role is set with SET LOCAL ROLE
+// and each of settings, e.g. "app.current_user_id", is set with set_config(..., true),
+// so row-level security policies apply to fn's queries. An empty role keeps the
+// current one.
+//
+// SET LOCAL only lasts until the end of the transaction, and a transaction runs on
+// a single connection, so the settings apply to exactly the queries fn issues through
+// the given DBTX and are reset when the connection goes back to the pool.
+// Queries issued through db inside fn don't see them.
+//
+// Example:
+//
+//	err := postgres.WithRole(ctx, db, "app_user", map[string]string{
+//		"app.current_user_id": strconv.FormatInt(userID, 10),
+//	}, func(tx xsql.DBTX) error {
+//		docs, err = xsql.QueryMany(ctx, tx, scanDoc, "SELECT id, title FROM documents")
+//		return err
+//	})
+func WithRole(
+	ctx context.Context,
+	db *sql.DB,
+	role string,
+	settings map[string]string,
+	fn func(xsql.DBTX) error,
+) error {
+	return xsql.WithTx(ctx, db, func(tx *sql.Tx) error {
+		if role != "" {
+			r, err := xsql.QuoteIdentifier(xsql.Postgres, role)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE "+r); err != nil {
+				return err
+			}
+		}
+
+		names := make([]string, 0, len(settings))
+		for name := range settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", name, settings[name]); err != nil {
+				xerror.Wrap(&err, "set %s", name)
+				return err
+			}
+		}
+
+		return fn(tx)
+	})
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/freakshake/xsql"
+)
+
+// explainTimeout bounds the EXPLAIN run by SeqScanHook.
+const explainTimeout = 10 * time.Second
+
+// SeqScanHook is a xsql.Hook flagging queries planned with a sequential scan
+// of a large table, which usually points at a missing index.
+// Create it with NewSeqScanHook. It is meant for development and CI, not production.
+type SeqScanHook struct {
+	db      xsql.DBTX
+	minRows float64
+	rate    float64
+	logger  *slog.Logger
+}
+
+// NewSeqScanHook returns a xsql.Hook which runs EXPLAIN (FORMAT JSON) on db for
+// queries which succeeded, and logs a warning with logger for every Seq Scan node
+// estimated to read more than minRows rows. A nil logger means slog.Default().
+//
+// Only a rate (0 to 1) fraction of the queries is explained, to cap the overhead.
+// Only SELECT statements are explained, since EXPLAIN of anything else may
+// change data, and EXPLAIN statements are never explained again, so db may be hooked itself.
+// The EXPLAIN runs in its own goroutine and doesn't delay the caller.
+//
+// Example:
+//
+//	db := xsql.WithHooks(sqlDB, postgres.NewSeqScanHook(sqlDB, 10000, 0.1, nil))
+func NewSeqScanHook(db xsql.DBTX, minRows float64, rate float64, logger *slog.Logger) *SeqScanHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SeqScanHook{db: db, minRows: minRows, rate: rate, logger: logger}
+}
+
+// Before implements xsql.Hook.
+func (h *SeqScanHook) Before(context.Context, xsql.QueryEvent) {}
+
+// After implements xsql.Hook.
+func (h *SeqScanHook) After(ctx context.Context, e xsql.QueryEvent) {
+	if e.Err != nil || !isSelect(e.Query) {
+		return
+	}
+	if rand.Float64() >= h.rate {
+		return
+	}
+
+	args := slices.Clone(e.Args)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), explainTimeout)
+		defer cancel()
+
+		var out []byte
+		err := h.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+e.Query, args...).Scan(&out)
+		if err != nil {
+			h.logger.WarnContext(ctx, "postgres: explain query", slog.String("query", e.Query), slog.Any("error", err))
+			return
+		}
+		var plans []struct {
+			Plan planNode `json:"Plan"`
+		}
+		if err := json.Unmarshal(out, &plans); err != nil {
+			h.logger.WarnContext(ctx, "postgres: parse query plan", slog.String("query", e.Query), slog.Any("error", err))
+			return
+		}
+		for _, p := range plans {
+			p.Plan.walk(func(n *planNode) {
+				if n.NodeType == "Seq Scan" && n.PlanRows > h.minRows {
+					h.logger.WarnContext(ctx, "postgres: sequential scan",
+						slog.String("query", e.Query),
+						slog.String("table", n.table()),
+						slog.Float64("rows", n.PlanRows),
+					)
+				}
+			})
+		}
+	}()
+}
+
+// planNode is a node of a JSON query plan.
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Schema       string     `json:"Schema"`
+	PlanRows     float64    `json:"Plan Rows"`
+	TotalCost    float64    `json:"Total Cost"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// walk calls fn for n and all the nodes below it.
+func (n *planNode) walk(fn func(*planNode)) {
+	fn(n)
+	for i := range n.Plans {
+		n.Plans[i].walk(fn)
+	}
+}
+
+// table returns the name of the table scanned by n.
+func (n *planNode) table() string {
+	if n.Schema != "" {
+		return n.Schema + "." + n.RelationName
+	}
+	return n.RelationName
+}
+
+// isSelect reports whether query is a SELECT statement.
+func isSelect(query string) bool {
+	q := strings.TrimLeft(query, " \t\r\n(")
+	return len(q) >= 6 && strings.EqualFold(q[:6], "SELECT")
+}
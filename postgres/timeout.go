@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/freakshake/xerror"
+	"github.com/freakshake/xsql"
+)
+
+// WithStatementTimeout runs fn on a single connection whose statement_timeout is d,
+// so the server cancels any of fn's statements running longer than d even if
+// the Go context is not honoured.
+//
+// The timeout is set with SET statement_timeout on a connection taken from the pool
+// and reset before the connection is returned to it. If the reset fails the
+// connection is discarded instead, so the setting can't leak to other queries.
+//
+// d is rounded up to whole milliseconds, the unit of statement_timeout, and must
+// be positive since statement_timeout = 0 disables the timeout.
+//
+// Example:
+//
+//	err := postgres.WithStatementTimeout(ctx, db, 2*time.Second, func(conn xsql.DBTX) error {
+//		report, err = xsql.QueryMany(ctx, conn, scanRow, "SELECT ...")
+//		return err
+//	})
+func WithStatementTimeout(ctx context.Context, db *sql.DB, d time.Duration, fn func(xsql.DBTX) error) (err error) {
+	if d <= 0 {
+		return fmt.Errorf("postgres: statement timeout must be positive, got %s", d)
+	}
+	ms := timeoutMillis(d)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := conn.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "conn.Close(): %s", cerr.Error())
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET statement_timeout = %d", ms)); err != nil {
+		return err
+	}
+	defer func() {
+		// Reset even when ctx is cancelled, the connection goes back to the pool.
+		if _, rerr := conn.ExecContext(context.WithoutCancel(ctx), "RESET statement_timeout"); rerr != nil {
+			_ = conn.Raw(func(any) error {
+				return driver.ErrBadConn
+			})
+		}
+	}()
+
+	return fn(conn)
+}
+
+// timeoutMillis returns d in milliseconds, rounded up so that a positive d never becomes 0.
+func timeoutMillis(d time.Duration) int64 {
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int64
+	}{
+		{time.Nanosecond, 1},
+		{999 * time.Microsecond, 1},
+		{time.Millisecond, 1},
+		{time.Millisecond + time.Nanosecond, 2},
+		{2 * time.Second, 2000},
+	}
+	for _, tt := range tests {
+		if got := timeoutMillis(tt.d); got != tt.want {
+			t.Errorf("timeoutMillis(%s) = %d, want %d", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestWithStatementTimeoutRejectsNonPositive(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		// The check runs before a connection is taken, so no database is needed.
+		if err := WithStatementTimeout(context.Background(), nil, d, nil); err == nil {
+			t.Errorf("WithStatementTimeout(%s) succeeded, want an error", d)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// CallProc calls the stored procedure proc with the in arguments followed by the
+// out parameters, which are bound with sql.Out and filled by the driver:
+//
+//	EXEC proc @in1, @in2, @out1 OUTPUT
+//
+// This is the SQL Server syntax, where OUT parameters are supported by the
+// driver (github.com/microsoft/go-mssqldb) through sql.Out. Other databases differ:
+//
+//   - MySQL: go-sql-driver/mysql does not support sql.Out. Pass session variables
+//     instead, CALL proc(?, @out), and read them with SELECT @out.
+//   - Postgres: lib/pq does not support sql.Out either. CALL returns OUT and INOUT
+//     parameters as a result row, read it with QueryOne.
+//
+// proc may be schema qualified, e.g. "dbo.GetTotals", but is rejected if it
+// contains anything else than letters, digits, '_', '.', '$', '#' and '@'.
+//
+// Example:
+//
+//	var total any
+//	err := CallProc(ctx, db, "dbo.OrderTotal", []any{orderID}, []*any{&total})
+func CallProc(ctx context.Context, db DBTX, proc string, in []any, out []*any) error {
+	if !isProcName(proc) {
+		return fmt.Errorf("%w: procedure %q", ErrInvalidIdentifier, proc)
+	}
+
+	params := make([]string, 0, len(in)+len(out))
+	args := make([]any, 0, len(in)+len(out))
+	for i, v := range in {
+		name := fmt.Sprintf("in%d", i+1)
+		params = append(params, "@"+name)
+		args = append(args, sql.Named(name, v))
+	}
+	for i, dest := range out {
+		name := fmt.Sprintf("out%d", i+1)
+		params = append(params, "@"+name+" OUTPUT")
+		args = append(args, sql.Named(name, sql.Out{Dest: dest}))
+	}
+
+	query := "EXEC " + proc
+	if len(params) > 0 {
+		query += " " + strings.Join(params, ", ")
+	}
+	_, err := db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func isProcName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == '$', r == '#', r == '@':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCallProc(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		proc  string
+		in    []any
+		nOut  int
+		query string
+		out   []any
+	}{
+		{"no params", "dbo.Refresh", nil, 0, "EXEC dbo.Refresh", []any{}},
+		{"in only", "Touch", []any{int64(1)}, 0, "EXEC Touch @in1", []any{}},
+		{
+			"in and out", "dbo.OrderTotal", []any{int64(42), "EUR"}, 2,
+			"EXEC dbo.OrderTotal @in1, @in2, @out1 OUTPUT, @out2 OUTPUT",
+			[]any{int64(42), "EUR"},
+		},
+		{"out only", "#tmp_proc", nil, 1, "EXEC #tmp_proc @out1 OUTPUT", []any{nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeDB()
+			db := f.open(t)
+
+			outs := make([]any, tt.nOut)
+			dests := make([]*any, tt.nOut)
+			for i := range outs {
+				outs[i] = "unset"
+				dests[i] = &outs[i]
+			}
+			if err := CallProc(ctx, db, tt.proc, tt.in, dests); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.executed(); len(got) != 1 || got[0] != tt.query {
+				t.Errorf("executed %q, want %q", got, tt.query)
+			}
+			if !slices.Equal(outs, tt.out) {
+				t.Errorf("OUT values = %v, want %v", outs, tt.out)
+			}
+		})
+	}
+}
+
+func TestCallProcInvalidName(t *testing.T) {
+	f := newFakeDB()
+	db := f.open(t)
+	for _, proc := range []string{"", "dbo.Run; DROP TABLE users", "proc name", "x'--"} {
+		if err := CallProc(context.Background(), db, proc, nil, nil); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("CallProc(%q) error = %v, want %v", proc, err, ErrInvalidIdentifier)
+		}
+	}
+	if got := f.executed(); len(got) != 0 {
+		t.Errorf("executed %q, want nothing", got)
+	}
+}
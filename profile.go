@@ -0,0 +1,68 @@
+package xsql
+
+import (
+	"context"
+
+	"github.com/freakshake/xerror"
+)
+
+// ProfileNulls runs the query and returns the number of NULL values of every
+// column, keyed by column name, along with the number of rows.
+// Columns sharing a name are counted together, alias them to tell them apart.
+//
+// Rows are streamed and never held in memory, but every row of the result is
+// read, so add a LIMIT or TABLESAMPLE to profile a sample of a large table.
+//
+// Example:
+//
+//	nulls, total, err := ProfileNulls(ctx, db, "SELECT * FROM customers LIMIT 100000")
+//	if err != nil {
+//		panic(err)
+//	}
+//	for col, n := range nulls {
+//		fmt.Printf("%s: %.1f%% NULL\n", col, 100*float64(n)/float64(total))
+//	}
+func ProfileNulls(ctx context.Context, db DBTX, query string, args ...any) (_ map[string]int64, total int64, err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, 0, err
+	}
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range dest {
+		dest[i] = &values[i]
+	}
+	counts := make([]int64, len(cols))
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+		total++
+		for i, v := range values {
+			if v == nil {
+				counts[i]++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	nulls := make(map[string]int64, len(cols))
+	for i, col := range cols {
+		nulls[col] += counts[i]
+	}
+	return nulls, total, nil
+}
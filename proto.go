@@ -0,0 +1,101 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QueryManyProto runs the query and scans every row positionally into a copy of proto,
+// which must be a struct. It is faster than ScanStruct, which maps columns by name,
+// and needs no scan function.
+//
+// The positional contract: the n-th column of the SELECT is scanned into the n-th
+// exported field of T in declaration order. Fields of embedded structs take the
+// place of the embedded field, following the rules of ScanStruct: embedded
+// pointers are allocated, embedded structs with a `db` tag, implementing
+// sql.Scanner or of type time.Time are one field, and the fields of structs
+// tagged with the prefix option are flattened too. Fields tagged `db:"-"` are
+// skipped. The number of columns must equal the number of fields, so only use
+// it when the SELECT column order is under your control.
+//
+// Fields not overwritten by a column keep the value they have in proto.
+// The structs behind embedded pointers of proto are copied for every row.
+//
+// Example:
+//
+//	type User struct {
+//		ID   int64
+//		Name string
+//	}
+//	users, err := QueryManyProto(ctx, db, User{}, "SELECT id, name FROM users")
+func QueryManyProto[T any](
+	ctx context.Context,
+	db DBTX,
+	proto T,
+	query string,
+	args ...any,
+) ([]T, error) {
+	t := reflect.TypeOf(proto)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("xsql: QueryManyProto: %T is not a struct", proto)
+	}
+	var fields, ptrs [][]int
+	positionalFields(t, nil, &fields, &ptrs)
+
+	scan := func(s Scanner) (T, error) {
+		row := proto
+		v := reflect.ValueOf(&row).Elem()
+		// Parents come before their children in ptrs, so a child is
+		// read from the copy of its parent.
+		for _, idx := range ptrs {
+			p := fieldByIndex(v, idx)
+			if !p.IsNil() {
+				c := reflect.New(p.Type().Elem())
+				c.Elem().Set(p.Elem())
+				p.Set(c)
+			}
+		}
+		dest := make([]any, len(fields))
+		for i, idx := range fields {
+			dest[i] = fieldByIndex(v, idx).Addr().Interface()
+		}
+		return row, s.Scan(dest...)
+	}
+
+	return queryMany(ctx, db, scan, nil, query, args)
+}
+
+// positionalFields appends the index paths of the exported fields of t to
+// fields in declaration order, with embedded structs flattened like
+// collectFields does, and the paths of the embedded pointers to ptrs.
+func positionalFields(t reflect.Type, index []int, fields, ptrs *[][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		_, opt, _ := strings.Cut(tag, ",")
+		idx := append(append([]int(nil), index...), i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		embedded := f.Anonymous && !hasTag && ft.Kind() == reflect.Struct && !isColumnStruct(ft)
+		// A nil pointer to an unexported struct type can't be allocated.
+		if !f.IsExported() && (!embedded || f.Type.Kind() == reflect.Pointer) {
+			continue
+		}
+		if embedded || (opt == "prefix" && ft.Kind() == reflect.Struct) {
+			if f.Type.Kind() == reflect.Pointer {
+				*ptrs = append(*ptrs, idx)
+			}
+			positionalFields(ft, idx, fields, ptrs)
+			continue
+		}
+		*fields = append(*fields, idx)
+	}
+}
@@ -0,0 +1,52 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryManyProtoEmbeddedPointer(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().
+		on("SELECT version, id", []string{"version", "id"},
+			[]driver.Value{int64(10), int64(1)},
+			[]driver.Value{int64(20), int64(2)}).
+		open(t)
+
+	type row struct {
+		*Versioned
+		ID int64 `db:"id"`
+	}
+
+	tests := []struct {
+		name  string
+		proto row
+	}{
+		{"nil pointer", row{}},
+		{"set pointer", row{Versioned: &Versioned{Version: 99}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryManyProto(ctx, db, tt.proto, "SELECT version, id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || got[0].Version != 10 || got[0].ID != 1 || got[1].Version != 20 || got[1].ID != 2 {
+				t.Fatalf("QueryManyProto = %+v, want versions 10 and 20", got)
+			}
+			if tt.proto.Versioned != nil && tt.proto.Version != 99 {
+				t.Errorf("QueryManyProto overwrote the embedded struct of proto with %d", tt.proto.Version)
+			}
+
+			// ScanStruct maps the same fields, by name.
+			byName, err := QueryMany(ctx, db, ScanStruct[row], "SELECT version, id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *byName[1].Versioned != *got[1].Versioned || byName[1].ID != got[1].ID {
+				t.Errorf("ScanStruct = %+v, QueryManyProto = %+v", byName[1], got[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimited is a DBTX capping the rate at which statements are issued,
+// to protect a database which can't take more. Create it with NewRateLimited.
+//
+// Every statement waits for a token of the limiter before it is issued, so all
+// code sharing a RateLimited handle shares a single budget of statements per second.
+// Reading the rows of a query is not limited.
+//
+// Example:
+//
+//	db := NewRateLimited(sqlDB, 50, 10)
+//	users, err := QueryMany(ctx, db, scanUser, "SELECT id, name FROM users")
+type RateLimited struct {
+	db      DBTX
+	limiter *rate.Limiter
+}
+
+// NewRateLimited returns a RateLimited issuing at most limit statements per second
+// on db, with bursts of up to burst statements.
+func NewRateLimited(db DBTX, limit rate.Limit, burst int) *RateLimited {
+	return &RateLimited{db: db, limiter: rate.NewLimiter(limit, burst)}
+}
+
+// SetLimit changes the rate limit, e.g. while the database is under maintenance.
+// Statements already waiting pick up the new limit.
+func (r *RateLimited) SetLimit(limit rate.Limit) {
+	r.limiter.SetLimit(limit)
+}
+
+// SetBurst changes the burst size.
+func (r *RateLimited) SetBurst(burst int) {
+	r.limiter.SetBurst(burst)
+}
+
+// Limit returns the current rate limit.
+func (r *RateLimited) Limit() rate.Limit {
+	return r.limiter.Limit()
+}
+
+// ExecContext waits for a token and executes the statement on the wrapped DBTX.
+// It returns the error of the wait without issuing the statement if ctx is
+// cancelled first, or would expire before a token is available.
+func (r *RateLimited) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+// QueryContext waits for a token and runs the query on the wrapped DBTX.
+// It returns the error of the wait without issuing the query if ctx is
+// cancelled first, or would expire before a token is available.
+func (r *RateLimited) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext waits for a token and runs the query on the wrapped DBTX.
+// A sql.Row can't carry an arbitrary error, so when the wait fails the query is
+// passed on with a context which is done: ctx itself if it was cancelled or
+// expired, or an expired copy of it if its deadline would pass before a token is
+// available, so the Row reports context.DeadlineExceeded.
+// QueryOne and Row use QueryRowErrContext instead.
+func (r *RateLimited) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if err := r.limiter.Wait(ctx); err != nil && ctx.Err() == nil {
+		dctx, cancel := context.WithDeadline(ctx, time.Time{})
+		defer cancel()
+		ctx = dctx
+	}
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+// QueryRowErrContext implements RowErrQuerier. It waits for a token like
+// QueryContext, returning the error of the wait without issuing the query,
+// and runs the query on the wrapped DBTX.
+func (r *RateLimited) QueryRowErrContext(ctx context.Context, query string, args ...any) (*sql.Row, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.db.QueryRowContext(ctx, query, args...), nil
+}
@@ -0,0 +1,57 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedSpacesCalls(t *testing.T) {
+	ctx := context.Background()
+	db := NewRateLimited(openTestDB(t), 50, 1)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := QueryOne(ctx, db, ScanID[int64], "SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first call uses the burst, the 4 others wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("5 calls at 50/s with a burst of 1 took %s, want about 80ms", elapsed)
+	}
+}
+
+func TestRateLimitedWaitErrors(t *testing.T) {
+	db := NewRateLimited(openTestDB(t), 1, 1)
+	if _, err := QueryOne(context.Background(), db, ScanID[int64], "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next token is a second away, past the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := QueryOne(ctx, db, ScanID[int64], "SELECT 1"); err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("QueryOne error = %v, want the wait error", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(new(int64)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QueryRowContext error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT 1"); err == nil {
+		t.Error("ExecContext succeeded, want the wait error")
+	}
+
+	cancel()
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(new(int64)); !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryRowContext error after cancel = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRateLimitedSetLimit(t *testing.T) {
+	db := NewRateLimited(openTestDB(t), 1, 1)
+	db.SetLimit(1000)
+	if got := db.Limit(); got != 1000 {
+		t.Errorf("Limit() = %v, want 1000", got)
+	}
+}
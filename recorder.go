@@ -0,0 +1,122 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// QueryRecord is a single statement captured by a Recorder.
+type QueryRecord struct {
+	// Op is the DBTX method used: "exec", "query" or "query_row".
+	Op    string
+	Query string
+	Args  []ArgShape
+}
+
+// ArgShape describes an argument of a recorded statement.
+type ArgShape struct {
+	// Type is the Go type of the argument, e.g. "int64" or "[]uint8".
+	Type string
+	// Len is the length of string, slice and map arguments, -1 otherwise.
+	Len int
+	// Value is the argument itself.
+	// It is only set when the Recorder was created with CaptureValues.
+	Value any
+}
+
+// Recorder is a DBTX which records every statement issued through it,
+// in order, before passing it to the wrapped DBTX.
+// It is meant to trace the database access of a single request so it
+// can be replayed elsewhere.
+//
+// By default only the shape of the arguments is recorded, never their values,
+// so the trace is safe to log. Use CaptureValues in development to record values too.
+//
+// A Recorder is safe for concurrent use by multiple goroutines.
+//
+// Example:
+//
+//	rec := NewRecorder(db)
+//	users, err := QueryMany(ctx, rec, scanUser, "SELECT id, name FROM users WHERE age = ?", 34)
+//	for _, r := range rec.Recorded() {
+//		log.Println(r.Query, r.Args)
+//	}
+type Recorder struct {
+	db      DBTX
+	values  bool
+	mu      sync.Mutex
+	records []QueryRecord
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// CaptureValues makes the Recorder store argument values.
+// Values may contain personal data, only use it in development.
+func CaptureValues() RecorderOption {
+	return func(r *Recorder) {
+		r.values = true
+	}
+}
+
+// NewRecorder returns a Recorder wrapping db.
+func NewRecorder(db DBTX, opts ...RecorderOption) *Recorder {
+	r := &Recorder{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ExecContext records the statement and executes it on the wrapped DBTX.
+func (r *Recorder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r.record("exec", query, args)
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+// QueryContext records the query and runs it on the wrapped DBTX.
+func (r *Recorder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	r.record("query", query, args)
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext records the query and runs it on the wrapped DBTX.
+func (r *Recorder) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	r.record("query_row", query, args)
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+// Recorded returns a copy of the statements recorded so far, in issue order.
+func (r *Recorder) Recorded() []QueryRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]QueryRecord(nil), r.records...)
+}
+
+// Reset discards all recorded statements.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+func (r *Recorder) record(op, query string, args []any) {
+	shapes := make([]ArgShape, len(args))
+	for i, arg := range args {
+		shapes[i] = ArgShape{Type: fmt.Sprintf("%T", arg), Len: -1}
+		switch v := reflect.ValueOf(arg); v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			shapes[i].Len = v.Len()
+		}
+		if r.values {
+			shapes[i].Value = arg
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, QueryRecord{Op: op, Query: query, Args: shapes})
+}
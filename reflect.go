@@ -0,0 +1,408 @@
+package xsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ColumnScanner is a Scanner which also knows its result columns.
+// e.g. sql.Rows implements ColumnScanner.
+// The reflection based scanners need it to map columns by name.
+type ColumnScanner interface {
+	Scanner
+	Columns() ([]string, error)
+	ColumnTypes() ([]*sql.ColumnType, error)
+}
+
+// ErrNoColumns is returned by the reflection based scanners when the Scanner
+// does not expose its columns, e.g. the sql.Row used by QueryOne.
+var ErrNoColumns = errors.New("xsql: scanner does not expose columns")
+
+// ScanMap scans the current row into a map keyed by column name.
+// Columns with a decoder in DefaultRegistry are decoded by it,
+// other values are stored as returned by the driver.
+//
+// ScanMap needs a ColumnScanner, so it is meant to be used with QueryMany.
+//
+// Example:
+//
+//	rows, err := QueryMany(ctx, db, ScanMap, "SELECT * FROM users")
+func ScanMap(s Scanner) (map[string]any, error) {
+	cs, ok := s.(ColumnScanner)
+	if !ok {
+		return nil, ErrNoColumns
+	}
+	cols, decs, err := columnsOf(cs)
+	if err != nil {
+		return nil, err
+	}
+	values, err := scanValues(s, cols, decs)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(cols))
+	for i, col := range cols {
+		m[col] = values[i]
+	}
+	return m, nil
+}
+
+// ScanValues scans the current row into a slice holding a value per column.
+// Columns with a decoder in DefaultRegistry are decoded by it,
+// other values are stored as returned by the driver.
+//
+// ScanValues needs a ColumnScanner, so it is meant to be used with QueryMany.
+func ScanValues(s Scanner) ([]any, error) {
+	cs, ok := s.(ColumnScanner)
+	if !ok {
+		return nil, ErrNoColumns
+	}
+	cols, decs, err := columnsOf(cs)
+	if err != nil {
+		return nil, err
+	}
+	return scanValues(s, cols, decs)
+}
+
+// ScanError is returned by ScanMap and ScanValues in Debug mode when a column
+// can not be decoded. It holds the values of the columns before the failing one,
+// which helps to find a type mismatch in a wide SELECT.
+// It is a debugging aid, don't rely on it in normal operation.
+type ScanError struct {
+	// Column is the index of the failing column.
+	Column int
+	// Name is the name of the failing column.
+	Name string
+	// Prefix holds the values of the columns before Column.
+	Prefix []any
+	Err    error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("xsql: decode column %d %q (after %v): %s", e.Column, e.Name, e.Prefix, e.Err)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+func scanValues(s Scanner, cols []string, decs []Decoder) ([]any, error) {
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range dest {
+		dest[i] = &values[i]
+		if decs[i] != nil {
+			dest[i] = new([]byte)
+		}
+	}
+	if err := s.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	for i, col := range cols {
+		if decs[i] == nil {
+			continue
+		}
+		raw := *dest[i].(*[]byte)
+		if raw == nil {
+			continue
+		}
+		v, err := decs[i](raw)
+		if err != nil {
+			if Debug {
+				return nil, &ScanError{Column: i, Name: col, Prefix: values[:i], Err: err}
+			}
+			return nil, fmt.Errorf("xsql: decode column %q: %w", col, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// ScanStruct scans the current row into a new T, which must be a struct.
+// Anonymous struct types are supported, see Select.
+//
+// Columns are matched to fields by the `db` struct tag, or by the lower cased
+// field name when the tag is absent. Fields tagged `db:"-"` and unexported
+// fields are ignored. Fields of embedded structs are promoted as if they were
+// declared in T, except behind pointers to unexported struct types, which can't
+// be allocated. Embedded structs implementing sql.Scanner and an embedded
+// time.Time are a single column named after their type, e.g. "nullstring".
+// Every column must have a matching field.
+//
+// A struct field tagged `db:"name,prefix"`, embedded or not, maps the columns
+// named "name.column" to its own fields, so the columns of joined tables can be
+// split into nested structs. Columns have to be aliased with the prefix, quoted as
+// the name contains a dot, e.g. SELECT u.id AS "user.id", a.id AS "addr.id".
+// Prefixes nest, as in "user.addr.city". Columns without a prefix only match
+// fields of T and of its untagged embedded structs, never the fields of a
+// prefixed struct, so an id selected twice has to be aliased to tell them apart.
+// Struct fields tagged without the prefix option are a single column, e.g. one
+// filled by a decoder of the Registry.
+// In Debug mode every field must have a matching column too, and a mismatch
+// is reported with both counts and the unmatched names.
+// Columns with a decoder in DefaultRegistry are decoded by it.
+//
+// ScanStruct needs a ColumnScanner, so it is meant to be used with QueryMany.
+//
+// Example:
+//
+//	type User struct {
+//		ID   int64  `db:"id"`
+//		Name string `db:"name"`
+//	}
+//	users, err := QueryMany(ctx, db, ScanStruct[User], "SELECT id, name FROM users")
+//
+//	type UserAddress struct {
+//		User    User    `db:"user,prefix"`
+//		Address Address `db:"addr,prefix"`
+//	}
+//	rows, err := QueryMany(ctx, db, ScanStruct[UserAddress], `SELECT
+//		u.id AS "user.id", u.name AS "user.name", a.id AS "addr.id", a.city AS "addr.city"
+//		FROM users u JOIN addresses a ON a.user_id = u.id`)
+func ScanStruct[T any](s Scanner) (t T, err error) {
+	cs, ok := s.(ColumnScanner)
+	if !ok {
+		return t, ErrNoColumns
+	}
+	cols, decs, err := columnsOf(cs)
+	if err != nil {
+		return t, err
+	}
+
+	v := reflect.ValueOf(&t).Elem()
+	if v.Kind() != reflect.Struct {
+		return t, fmt.Errorf("xsql: ScanStruct: %s is not a struct", v.Type())
+	}
+	fields := fieldsOf(v.Type())
+	if Debug {
+		if err := checkColumns(v.Type(), cols, fields); err != nil {
+			return t, err
+		}
+	}
+
+	dest := make([]any, len(cols))
+	for i, col := range cols {
+		idx, ok := fields[col]
+		if !ok {
+			return t, fmt.Errorf("xsql: missing destination field for column %q in %s", col, v.Type())
+		}
+		if decs[i] != nil {
+			dest[i] = new([]byte)
+			continue
+		}
+		dest[i] = fieldByIndex(v, idx).Addr().Interface()
+	}
+	if err := s.Scan(dest...); err != nil {
+		return t, err
+	}
+
+	for i, col := range cols {
+		if decs[i] == nil {
+			continue
+		}
+		raw := *dest[i].(*[]byte)
+		if raw == nil {
+			continue
+		}
+		dv, err := decs[i](raw)
+		if err != nil {
+			return t, fmt.Errorf("xsql: decode column %q: %w", col, err)
+		}
+		if err := assign(fieldByIndex(v, fields[col]), dv); err != nil {
+			return t, fmt.Errorf("xsql: column %q: %w", col, err)
+		}
+	}
+	return t, nil
+}
+
+// checkColumns reports an error unless cols and fields match one to one.
+// It is only run in Debug mode.
+func checkColumns(t reflect.Type, cols []string, fields map[string][]int) error {
+	var unmatched []string
+	selected := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		selected[col] = true
+		if _, ok := fields[col]; !ok {
+			unmatched = append(unmatched, col)
+		}
+	}
+	if len(unmatched) == 0 && len(cols) == len(fields) {
+		return nil
+	}
+
+	var unselected []string
+	for name := range fields {
+		if !selected[name] {
+			unselected = append(unselected, name)
+		}
+	}
+	sort.Strings(unselected)
+
+	return fmt.Errorf(
+		"xsql: %d columns but %s has %d mapped fields, unmatched columns: %q, unselected fields: %q",
+		len(cols), t, len(fields), unmatched, unselected,
+	)
+}
+
+// columnsOf returns the column names of cs and, for every column,
+// the decoder registered for its database type or nil.
+func columnsOf(cs ColumnScanner) ([]string, []Decoder, error) {
+	cols, err := cs.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	types, err := cs.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	decs := make([]Decoder, len(cols))
+	for i, ct := range types {
+		if dec, ok := DefaultRegistry.Lookup(ct.DatabaseTypeName()); ok {
+			decs[i] = dec
+		}
+	}
+	return cols, decs, nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]map[string][]int
+
+// fieldsOf returns the index path of every mappable field of the struct type t
+// keyed by column name. The result is cached per type.
+func fieldsOf(t reflect.Type) map[string][]int {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	collectFields(t, nil, "", fields)
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.(map[string][]int)
+}
+
+func collectFields(t reflect.Type, index []int, prefix string, fields map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		tag, opt, _ := strings.Cut(tag, ",")
+		idx := append(append([]int(nil), index...), i)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !hasTag && ft.Kind() == reflect.Struct && !isColumnStruct(ft) {
+			// A nil pointer to an unexported struct type can't be allocated.
+			if f.Type.Kind() != reflect.Pointer || f.IsExported() {
+				collectFields(ft, idx, prefix, fields)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if opt == "prefix" && ft.Kind() == reflect.Struct {
+			collectFields(ft, idx, prefix+tag+".", fields)
+			continue
+		}
+
+		name := prefix + tag
+		if !hasTag {
+			name = prefix + strings.ToLower(f.Name)
+		}
+		// Shallower fields win over promoted ones, like in Go itself.
+		if prev, ok := fields[name]; !ok || len(idx) < len(prev) {
+			fields[name] = idx
+		}
+	}
+}
+
+// scannerType and timeType are the struct types scanned as a single column.
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+)
+
+// isColumnStruct reports whether the struct type t is scanned as a single
+// column rather than flattened into its fields.
+func isColumnStruct(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// structColumns returns the columns of the struct type t, as mapped by
+// ScanStruct, in field declaration order, along with the index path of their field.
+func structColumns(t reflect.Type) ([]string, [][]int) {
+	fields := fieldsOf(t)
+	cols := make([]string, 0, len(fields))
+	for name := range fields {
+		cols = append(cols, name)
+	}
+	sort.Slice(cols, func(i, j int) bool {
+		a, b := fields[cols[i]], fields[cols[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	index := make([][]int, len(cols))
+	for i, col := range cols {
+		index[i] = fields[col]
+	}
+	return cols, index
+}
+
+// fieldValues returns the values of the fields of v at index.
+// Fields below a nil embedded pointer are nil.
+func fieldValues(v reflect.Value, index [][]int) []any {
+	values := make([]any, len(index))
+	for i, idx := range index {
+		f, err := v.FieldByIndexErr(idx)
+		if err != nil {
+			continue
+		}
+		values[i] = f.Interface()
+	}
+	return values
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex,
+// but allocates nil embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// assign sets field to the decoded value v, converting it when needed.
+func assign(field reflect.Value, v any) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		field.Set(reflect.Zero(field.Type()))
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Type().ConvertibleTo(field.Type()):
+		field.Set(rv.Convert(field.Type()))
+	default:
+		return fmt.Errorf("can not assign %s to field of type %s", rv.Type(), field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type versioned struct {
+	Version int64
+}
+
+func TestScanStructEmbeddedPointer(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	exported, err := QueryMany(ctx, db, ScanStruct[struct {
+		*Versioned
+		ID int64 `db:"id"`
+	}], "SELECT 1 AS id, 2 AS version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := exported[0]; r.ID != 1 || r.Versioned == nil || r.Version != 2 {
+		t.Errorf("ScanStruct = %+v, want the embedded pointer allocated", r)
+	}
+
+	// Fields behind a pointer to an unexported struct are not mapped.
+	if _, err := QueryMany(ctx, db, ScanStruct[struct {
+		*versioned
+		ID int64 `db:"id"`
+	}], "SELECT 1 AS id, 2 AS version"); err == nil {
+		t.Error("ScanStruct mapped a column to a field behind a pointer to an unexported struct")
+	}
+}
+
+type prefixUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+type prefixAddress struct {
+	ID   int64  `db:"id"`
+	City string `db:"city"`
+	Geo  struct {
+		Lat float64 `db:"lat"`
+	} `db:"geo,prefix"`
+}
+
+type point struct {
+	X, Y float64
+}
+
+func TestScanStructPrefix(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().
+		on("SELECT joined", []string{"id", "user.id", "user.name", "addr.id", "addr.city", "addr.geo.lat"},
+			[]driver.Value{int64(10), int64(1), "ada", int64(10), "London", 51.5}).
+		on("SELECT unprefixed", []string{"id", "name"}, []driver.Value{int64(1), "ada"}).
+		on("SELECT plain", []string{"plain.x"}, []driver.Value{1.5}).
+		open(t)
+
+	type userAddress struct {
+		ID      int64         `db:"id"`
+		User    prefixUser    `db:"user,prefix"`
+		Address prefixAddress `db:"addr,prefix"`
+	}
+
+	got, err := QueryMany(ctx, db, ScanStruct[userAddress], "SELECT joined")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := userAddress{ID: 10, User: prefixUser{1, "ada"}, Address: prefixAddress{ID: 10, City: "London"}}
+	want.Address.Geo.Lat = 51.5
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("ScanStruct = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		// Un-prefixed columns never reach the fields of prefixed structs.
+		{"unprefixed column", "SELECT unprefixed"},
+		// Without the prefix option a struct field is one column.
+		{"prefix of a plain struct field", "SELECT plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type row struct {
+				ID    int64      `db:"id"`
+				User  prefixUser `db:"user,prefix"`
+				Plain point      `db:"plain"`
+			}
+			if got, err := QueryMany(ctx, db, ScanStruct[row], tt.query); err == nil {
+				t.Errorf("ScanStruct = %+v, want a missing field error", got)
+			}
+		})
+	}
+}
+
+func TestScanStructDecodedStructField(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	registerDecoder(t, "XSQLTEST_POINT", func(b []byte) (any, error) {
+		var p point
+		_, err := fmt.Sscanf(string(b), "%g,%g", &p.X, &p.Y)
+		return p, err
+	})
+	mustExec(t, db,
+		"CREATE TABLE places (name TEXT, loc XSQLTEST_POINT)",
+		"INSERT INTO places VALUES ('home', '1.5,-2')",
+	)
+
+	type place struct {
+		Name string `db:"name"`
+		Loc  point  `db:"loc"`
+	}
+	got, err := QueryMany(ctx, db, ScanStruct[place], "SELECT name, loc FROM places")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (place{"home", point{1.5, -2}}); len(got) != 1 || got[0] != want {
+		t.Errorf("ScanStruct = %+v, want %+v", got, want)
+	}
+}
+
+func TestScanStructEmbeddedScanner(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db := newFakeDB().
+		on("SELECT row", []string{"nullstring", "time", "id"}, []driver.Value{"ada", at, int64(1)}).
+		open(t)
+
+	type row struct {
+		sql.NullString
+		time.Time
+		ID int64 `db:"id"`
+	}
+	if cols, _ := structColumns(reflect.TypeFor[row]()); !slices.Equal(cols, []string{"nullstring", "time", "id"}) {
+		t.Errorf("structColumns = %q, want the embedded scanners as single columns", cols)
+	}
+
+	got, err := QueryMany(ctx, db, ScanStruct[row], "SELECT row")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := got[0]; r.NullString != (sql.NullString{String: "ada", Valid: true}) || !r.Time.Equal(at) || r.ID != 1 {
+		t.Errorf("ScanStruct = %+v", r)
+	}
+}
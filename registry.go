@@ -0,0 +1,53 @@
+package xsql
+
+import (
+	"strings"
+	"sync"
+)
+
+// Decoder converts the raw bytes of a column value into a Go value.
+type Decoder func([]byte) (any, error)
+
+// Registry holds Decoders keyed by database column type name,
+// as reported by sql.ColumnType.DatabaseTypeName (e.g. "GEOMETRY").
+// Type names are matched case-insensitively.
+//
+// A Registry is safe for concurrent use by multiple goroutines,
+// decoders may be registered while queries are being scanned.
+//
+// A Registry is consulted only by the reflection based scanners,
+// ScanMap and ScanStruct. Hand written scan functions are never affected.
+type Registry struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+}
+
+// DefaultRegistry is the Registry used by ScanMap and ScanStruct.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[string]Decoder)}
+}
+
+// Register registers dec for columns of the given database type name.
+// It replaces any decoder previously registered for that type.
+//
+// Example:
+//
+//	xsql.DefaultRegistry.Register("GEOMETRY", func(b []byte) (any, error) {
+//		return geom.ParseWKB(b)
+//	})
+func (r *Registry) Register(typeName string, dec Decoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[strings.ToUpper(typeName)] = dec
+}
+
+// Lookup returns the decoder registered for the given database type name.
+func (r *Registry) Lookup(typeName string) (Decoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dec, ok := r.decoders[strings.ToUpper(typeName)]
+	return dec, ok
+}
@@ -0,0 +1,40 @@
+package xsql
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Lookup("GEOMETRY"); ok {
+		t.Fatal("Lookup found a decoder in an empty Registry")
+	}
+
+	r.Register("geometry", func([]byte) (any, error) { return 1, nil })
+	dec, ok := r.Lookup("GEOMETRY")
+	if !ok {
+		t.Fatal("Lookup is case-sensitive")
+	}
+	if v, _ := dec(nil); v != 1 {
+		t.Errorf("decoder = %v, want 1", v)
+	}
+
+	r.Register("Geometry", func([]byte) (any, error) { return 2, nil })
+	dec, _ = r.Lookup("geometry")
+	if v, _ := dec(nil); v != 2 {
+		t.Errorf("decoder = %v after Register replaced it, want 2", v)
+	}
+	if _, ok := DefaultRegistry.Lookup("GEOMETRY"); ok {
+		t.Error("a local Registry leaked into DefaultRegistry")
+	}
+}
+
+func TestRegisterDecoderRestores(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		registerDecoder(t, "XSQLTEST_TYPE", func([]byte) (any, error) { return nil, nil })
+		if _, ok := DefaultRegistry.Lookup("XSQLTEST_TYPE"); !ok {
+			t.Fatal("registerDecoder did not register")
+		}
+	})
+	if _, ok := DefaultRegistry.Lookup("XSQLTEST_TYPE"); ok {
+		t.Error("registerDecoder left its decoder in DefaultRegistry after the test")
+	}
+}
@@ -0,0 +1,51 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// QueryOneRetry is like QueryOne, but runs the query once more
+// if the first attempt failed with driver.ErrBadConn.
+//
+// database/sql already retries bad pooled connections in most cases,
+// but not when the error surfaces after the query was issued, e.g. while scanning.
+// Only one retry is made, so a persistently broken pool is not masked.
+//
+// There is on purpose no Exec equivalent: a statement may have been applied
+// before the connection broke, and running it again is only safe for idempotent reads.
+func QueryOneRetry[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) (T, error) {
+	return retryBadConn(func() (T, error) {
+		return QueryOne(ctx, db, scan, query, args...)
+	})
+}
+
+// QueryManyRetry is like QueryMany, but runs the query once more
+// if the first attempt failed with driver.ErrBadConn.
+// See QueryOneRetry.
+func QueryManyRetry[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) ([]T, error) {
+	return retryBadConn(func() ([]T, error) {
+		return QueryMany(ctx, db, scan, query, args...)
+	})
+}
+
+func retryBadConn[T any](read func() (T, error)) (T, error) {
+	res, err := read()
+	if errors.Is(err, driver.ErrBadConn) {
+		return read()
+	}
+	return res, err
+}
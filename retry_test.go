@@ -0,0 +1,49 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestRetryBadConn(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().on("SELECT 1", []string{"1"}, []driver.Value{int64(1)}).open(t)
+	errScan := errors.New("scan failed")
+
+	tests := []struct {
+		name  string
+		fails []error // errors of the successive scan attempts
+		calls int
+		err   error
+	}{
+		{"success", nil, 1, nil},
+		{"bad conn then success", []error{driver.ErrBadConn}, 2, nil},
+		{"bad conn twice", []error{driver.ErrBadConn, driver.ErrBadConn}, 2, driver.ErrBadConn},
+		{"other error", []error{errScan}, 1, errScan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			scan := func(s Scanner) (int64, error) {
+				calls++
+				n, err := ScanID[int64](s)
+				if calls <= len(tt.fails) {
+					return 0, tt.fails[calls-1]
+				}
+				return n, err
+			}
+
+			calls = 0
+			if _, err := QueryOneRetry(ctx, db, scan, "SELECT 1"); !errors.Is(err, tt.err) || calls != tt.calls {
+				t.Errorf("QueryOneRetry = %v after %d attempts, want %v after %d", err, calls, tt.err, tt.calls)
+			}
+
+			calls = 0
+			if _, err := QueryManyRetry(ctx, db, scan, "SELECT 1"); !errors.Is(err, tt.err) || calls != tt.calls {
+				t.Errorf("QueryManyRetry = %v after %d attempts, want %v after %d", err, calls, tt.err, tt.calls)
+			}
+		})
+	}
+}
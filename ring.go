@@ -0,0 +1,56 @@
+package xsql
+
+import (
+	"context"
+	"fmt"
+)
+
+// RingForEach runs the query and calls fn once per row with a sliding window
+// over the most recent rows, oldest first and ending with the current row.
+// It suits moving averages and comparisons with previous rows over results
+// too large to load, as only window rows are held at a time.
+//
+// fn sees up to window rows: fewer for the first window-1 rows, exactly window
+// after that. The slice is reused from one call to the next, so fn must copy
+// rows it keeps. RingForEach stops at the first error of scan or fn and returns it.
+//
+// Example:
+//
+//	// 7 day moving average
+//	err := RingForEach(ctx, db, scanDay, 7, func(days []Day) error {
+//		var sum float64
+//		for _, d := range days {
+//			sum += d.Revenue
+//		}
+//		fmt.Println(days[len(days)-1].Date, sum/float64(len(days)))
+//		return nil
+//	}, "SELECT date, revenue FROM daily_revenue ORDER BY date")
+func RingForEach[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	window int,
+	fn func(window []T) error,
+	query string,
+	args ...any,
+) error {
+	if window < 1 {
+		return fmt.Errorf("xsql: RingForEach: window must be positive, got %d", window)
+	}
+
+	// Every row is stored twice, window apart, so the window is always
+	// the contiguous slice buf[next-n+window : next+window].
+	buf := make([]T, 2*window)
+	next, n := 0, 0
+	return Stream(ctx, db, scan, func(v T) error {
+		buf[next] = v
+		buf[next+window] = v
+		next = (next + 1) % window
+		n = min(n+1, window)
+		end := next + window
+		if next == 0 {
+			end = window
+		}
+		return fn(buf[end-n : end])
+	}, query, args...)
+}
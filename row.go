@@ -0,0 +1,26 @@
+package xsql
+
+import (
+	"context"
+)
+
+// Row runs a query expected to return a single row and scans it into dest.
+// It returns ErrNotFound if the query returned no row.
+//
+// Row is the escape hatch for quick lookups where defining a type or a scan
+// function is overkill. Prefer QueryOne for anything else.
+//
+// Example:
+//
+//	var (
+//		name string
+//		age  int
+//	)
+//	err := Row(ctx, db, "SELECT name, age FROM users WHERE id = ?", []any{1}, &name, &age)
+func Row(ctx context.Context, db DBTX, query string, args []any, dest ...any) error {
+	row, err := queryRow(ctx, db, query, args)
+	if err != nil {
+		return err
+	}
+	return notFound(row.Scan(dest...))
+}
@@ -0,0 +1,247 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// maxPlaceholders is the number of placeholders a statement may hold,
+// the limit of both MySQL and the Postgres wire protocol.
+const maxPlaceholders = 65535
+
+// SaveAll inserts rows into table, updating the existing rows they conflict with,
+// and returns the number of rows affected. It is the "save a batch of entities"
+// primitive of sync jobs, running it twice with the same rows changes nothing.
+//
+// The columns come from the fields of T, mapped like ScanStruct does, in
+// declaration order. conflictCols are the columns of the unique key rows conflict on,
+// every other column is updated. When all columns are conflict columns, existing
+// rows are left as they are. Table and column names are quoted for dialect.
+//
+// For Postgres the statement is INSERT ... ON CONFLICT (conflictCols) DO UPDATE.
+// For MySQL it is INSERT ... ON DUPLICATE KEY UPDATE, which conflicts on any unique
+// key of the table, so conflictCols only decide which columns are not updated there,
+// and MySQL counts an updated row as 2 rows affected.
+//
+// Rows holding the same values in conflictCols are saved once, the last of them
+// wins, as Postgres refuses to update a row twice in one statement.
+// Rows are sent in as few statements as the placeholder limit allows,
+// all in one transaction, so either all rows are saved or none.
+// Empty rows is a no-op.
+//
+// Example:
+//
+//	type Product struct {
+//		SKU   string `db:"sku"`
+//		Name  string `db:"name"`
+//		Price int64  `db:"price"`
+//	}
+//	n, err := SaveAll(ctx, db, Postgres, "products", []string{"sku"}, products)
+func SaveAll[T any](
+	ctx context.Context,
+	db *sql.DB,
+	dialect Dialect,
+	table string,
+	conflictCols []string,
+	rows []T,
+) (affected int64, err error) {
+	stmts, err := UpsertBatch[T]{Table: table, ConflictCols: conflictCols, Rows: rows}.Statements(dialect)
+	if err != nil || len(stmts) == 0 {
+		return 0, err
+	}
+
+	err = WithTx(ctx, db, func(tx *sql.Tx) error {
+		for _, stmt := range stmts {
+			res, err := tx.ExecContext(ctx, stmt.Query, stmt.Args...)
+			PutArgs(stmt.Args)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// UpsertBatch is the Builder of the statements SaveAll runs.
+//
+// Example:
+//
+//	stmts, err := DryRun(Postgres, UpsertBatch[Product]{Table: "products", ConflictCols: []string{"sku"}, Rows: products})
+type UpsertBatch[T any] struct {
+	Table        string
+	ConflictCols []string
+	Rows         []T
+}
+
+// Statements implements Builder.
+func (b UpsertBatch[T]) Statements(dialect Dialect) ([]Statement, error) {
+	if len(b.Rows) == 0 {
+		return nil, nil
+	}
+	cols, index, err := batchColumns[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	var update []string
+	for _, col := range cols {
+		if !slices.Contains(b.ConflictCols, col) {
+			update = append(update, col)
+		}
+	}
+	keyIndex := make([][]int, len(b.ConflictCols))
+	for i, col := range b.ConflictCols {
+		j := slices.Index(cols, col)
+		if j < 0 {
+			return nil, fmt.Errorf("xsql: UpsertBatch: conflict column %q is not a field of %s", col, reflect.TypeFor[T]())
+		}
+		keyIndex[i] = index[j]
+	}
+	if len(b.ConflictCols) == 0 {
+		return nil, fmt.Errorf("xsql: UpsertBatch: no conflict columns")
+	}
+
+	prefix, err := insertPrefix(dialect, b.Table, cols)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := upsertSuffix(dialect, b.ConflictCols, update)
+	if err != nil {
+		return nil, err
+	}
+	return batchStatements(dialect, lastByKey(b.Rows, keyIndex), index, prefix, suffix), nil
+}
+
+// lastByKey returns rows without the rows followed by one holding the same
+// values in the fields at keyIndex, keeping the order of the other rows.
+func lastByKey[T any](rows []T, keyIndex [][]int) []T {
+	seen := make(map[string]struct{}, len(rows))
+	kept := make([]T, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		key := fmt.Sprintf("%#v", fieldValues(reflect.ValueOf(rows[i]), keyIndex))
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		kept = append(kept, rows[i])
+	}
+	slices.Reverse(kept)
+	return kept
+}
+
+// batchColumns returns the columns of the struct type T, see structColumns.
+func batchColumns[T any]() ([]string, [][]int, error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("xsql: %s is not a struct", t)
+	}
+	cols, index := structColumns(t)
+	if len(cols) == 0 {
+		return nil, nil, fmt.Errorf("xsql: %s has no mapped fields", t)
+	}
+	return cols, index, nil
+}
+
+// batchStatements returns the statements inserting rows with as few
+// statements as the placeholder limit allows.
+func batchStatements[T any](dialect Dialect, rows []T, index [][]int, prefix, suffix string) []Statement {
+	var stmts []Statement
+	for chunk := range slices.Chunk(rows, maxPlaceholders/len(index)) {
+		values, args := valuesList(dialect, chunk, index)
+		stmts = append(stmts, Statement{Query: prefix + values + suffix, Args: args})
+	}
+	return stmts
+}
+
+// insertPrefix returns "INSERT INTO table (cols) VALUES " with the names quoted for dialect.
+func insertPrefix(dialect Dialect, table string, cols []string) (string, error) {
+	qTable, err := QuoteIdentifier(dialect, table)
+	if err != nil {
+		return "", err
+	}
+	qCols, err := quoteAll(dialect, cols)
+	if err != nil {
+		return "", err
+	}
+	return "INSERT INTO " + qTable + " (" + strings.Join(qCols, ", ") + ") VALUES ", nil
+}
+
+// upsertSuffix returns the conflict clause of SaveAll for dialect.
+func upsertSuffix(dialect Dialect, conflictCols, update []string) (string, error) {
+	qConflict, err := quoteAll(dialect, conflictCols)
+	if err != nil {
+		return "", err
+	}
+	qUpdate, err := quoteAll(dialect, update)
+	if err != nil {
+		return "", err
+	}
+
+	set := make([]string, len(qUpdate))
+	if dialect == Postgres {
+		for i, col := range qUpdate {
+			set[i] = col + " = EXCLUDED." + col
+		}
+		if len(set) == 0 {
+			return " ON CONFLICT (" + strings.Join(qConflict, ", ") + ") DO NOTHING", nil
+		}
+		return " ON CONFLICT (" + strings.Join(qConflict, ", ") + ") DO UPDATE SET " + strings.Join(set, ", "), nil
+	}
+
+	for i, col := range qUpdate {
+		set[i] = col + " = VALUES(" + col + ")"
+	}
+	if len(set) == 0 {
+		// A no-op update, MySQL has no DO NOTHING.
+		set = []string{qConflict[0] + " = " + qConflict[0]}
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", "), nil
+}
+
+// valuesList returns the VALUES tuples of rows in the placeholder style of dialect,
+// and their field values as args from GetArgs.
+func valuesList[T any](dialect Dialect, rows []T, index [][]int) (string, []any) {
+	var b strings.Builder
+	args := GetArgs(len(rows) * len(index))
+	for i := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		values := fieldValues(reflect.ValueOf(rows[i]), index)
+		var placeholders string
+		if dialect == Postgres {
+			placeholders, _ = InN(len(args)+1, values)
+		} else {
+			placeholders, _ = In(values)
+		}
+		b.WriteString("(" + placeholders + ")")
+		args = append(args, values...)
+	}
+	return b.String(), args
+}
+
+// quoteAll quotes every identifier of idents for dialect.
+func quoteAll(dialect Dialect, idents []string) ([]string, error) {
+	quoted := make([]string, len(idents))
+	for i, ident := range idents {
+		q, err := QuoteIdentifier(dialect, ident)
+		if err != nil {
+			return nil, err
+		}
+		quoted[i] = q
+	}
+	return quoted, nil
+}
@@ -0,0 +1,37 @@
+package xsql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpsertBatchLastRowWins(t *testing.T) {
+	type price struct {
+		Region string `db:"region"`
+		SKU    string `db:"sku"`
+		Price  int64  `db:"price"`
+	}
+	rows := []price{
+		{"eu", "a", 1},
+		{"us", "a", 2},
+		{"eu", "b", 3},
+		{"eu", "a", 4},
+		{"eu", "b", 5},
+	}
+
+	stmts, err := DryRun(Postgres, UpsertBatch[price]{Table: "prices", ConflictCols: []string{"region", "sku"}, Rows: rows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 1 {
+		t.Fatalf("%d statements, want 1", len(stmts))
+	}
+	want := Statement{
+		Query: `INSERT INTO "prices" ("region", "sku", "price") VALUES ($1,$2,$3), ($4,$5,$6), ($7,$8,$9)` +
+			` ON CONFLICT ("region", "sku") DO UPDATE SET "price" = EXCLUDED."price"`,
+		Args: []any{"us", "a", int64(2), "eu", "a", int64(4), "eu", "b", int64(5)},
+	}
+	if !reflect.DeepEqual(stmts[0], want) {
+		t.Errorf("UpsertBatch = %+v, want %+v", stmts[0], want)
+	}
+}
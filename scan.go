@@ -1,5 +1,7 @@
 package xsql
 
+import "database/sql"
+
 // Scanner is a type which can scan data to destinations.
 // e.g. sql.Rows implements Scanner.
 // It is used by scan function.
@@ -13,3 +15,63 @@ func ScanID[T any](s Scanner) (id T, err error) {
 	}
 	return id, nil
 }
+
+// ScanNonNull scans a single value like ScanID, but returns ErrNullResult
+// when the value is SQL NULL. It lets callers tell apart a missing row
+// (ErrNotFound from QueryOne), a NULL aggregate and an actual zero value.
+//
+// Example:
+//
+//	max, err := QueryOne(ctx, db, ScanNonNull[int64], "SELECT MAX(age) FROM users")
+//	if errors.Is(err, ErrNullResult) {
+//		// the table is empty
+//	}
+func ScanNonNull[T any](s Scanner) (v T, err error) {
+	var p *T
+	if err = s.Scan(&p); err != nil {
+		return v, err
+	}
+	if p == nil {
+		return v, ErrNullResult
+	}
+	return *p, nil
+}
+
+// CountingScanner is a Scanner recording how many destinations Scan was given,
+// to check a scan function against the columns of a query.
+// It passes the calls on to the wrapped Scanner. It is a ColumnScanner, so
+// ScanStruct and ScanMap work through it when the wrapped Scanner is one too.
+type CountingScanner struct {
+	Scanner
+	// Dests is the number of destinations of the last Scan call.
+	Dests int
+	// Calls is the number of Scan calls.
+	Calls int
+}
+
+// Scan records the destinations and scans into them with the wrapped Scanner.
+func (s *CountingScanner) Scan(dest ...any) error {
+	s.Dests = len(dest)
+	s.Calls++
+	return s.Scanner.Scan(dest...)
+}
+
+// Columns returns the columns of the wrapped Scanner,
+// or ErrNoColumns if it is not a ColumnScanner.
+func (s *CountingScanner) Columns() ([]string, error) {
+	cs, ok := s.Scanner.(ColumnScanner)
+	if !ok {
+		return nil, ErrNoColumns
+	}
+	return cs.Columns()
+}
+
+// ColumnTypes returns the column types of the wrapped Scanner,
+// or ErrNoColumns if it is not a ColumnScanner.
+func (s *CountingScanner) ColumnTypes() ([]*sql.ColumnType, error) {
+	cs, ok := s.Scanner.(ColumnScanner)
+	if !ok {
+		return nil, ErrNoColumns
+	}
+	return cs.ColumnTypes()
+}
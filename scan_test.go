@@ -0,0 +1,39 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestScanNonNull(t *testing.T) {
+	ctx := context.Background()
+	col := []string{"max"}
+	db := newFakeDB().
+		on("SELECT age FROM empty", []string{"age"}).
+		on("SELECT MAX(age) FROM empty", col, []driver.Value{nil}).
+		on("SELECT MAX(age) FROM zero", col, []driver.Value{int64(0)}).
+		on("SELECT 42", col, []driver.Value{int64(42)}).
+		open(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int64
+		err   error
+	}{
+		{"no row", "SELECT age FROM empty", 0, ErrNotFound},
+		{"null aggregate", "SELECT MAX(age) FROM empty", 0, ErrNullResult},
+		{"zero", "SELECT MAX(age) FROM zero", 0, nil},
+		{"value", "SELECT 42", 42, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryOne(ctx, db, ScanNonNull[int64], tt.query)
+			if got != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("QueryOne = %d, %v, want %d, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package xsql
+
+import (
+	"context"
+)
+
+// Select runs the query and scans all rows into dest with ScanStruct.
+// Since T is inferred from dest, it works with anonymous structs too,
+// which keeps one-off queries from needing a named type.
+//
+// Example:
+//
+//	var report []struct {
+//		Status string `db:"status"`
+//		Total  int64  `db:"total"`
+//	}
+//	err := Select(ctx, db, &report, "SELECT status, COUNT(*) AS total FROM orders GROUP BY status")
+func Select[T any](ctx context.Context, db DBTX, dest *[]T, query string, args ...any) error {
+	rows, err := queryMany(ctx, db, ScanStruct[T], nil, query, args)
+	if err != nil {
+		return err
+	}
+	*dest = rows
+	return nil
+}
+
+// Get runs the query and scans its first row into dest with ScanStruct.
+// It returns ErrNotFound if the query returned no row.
+// Like Select it works with anonymous structs.
+//
+// Example:
+//
+//	var user struct {
+//		ID   int64  `db:"id"`
+//		Name string `db:"name"`
+//	}
+//	err := Get(ctx, db, &user, "SELECT id, name FROM users WHERE id = ?", 1)
+func Get[T any](ctx context.Context, db DBTX, dest *T, query string, args ...any) error {
+	rows, err := queryMany(ctx, db, ScanStruct[T], nil, query, append(args[:len(args):len(args)], Take(1)))
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return ErrNotFound
+	}
+	*dest = rows[0]
+	return nil
+}
+
+// QueryStructsWith runs the query like QueryMany, scanning each row with ScanStruct,
+// and calls after on every scanned struct before it is added to the result.
+// It keeps computed fields, like a full name or an age, next to the query
+// which fetches their inputs. Fields tagged `db:"-"` are left for after to fill.
+//
+// Each row is scanned first, then passed to after, so after sees all the columns
+// of its row. An error of after aborts the query and is returned.
+//
+// Example:
+//
+//	type User struct {
+//		First    string `db:"first_name"`
+//		Last     string `db:"last_name"`
+//		FullName string `db:"-"`
+//	}
+//	users, err := QueryStructsWith(ctx, db, func(u *User) error {
+//		u.FullName = u.First + " " + u.Last
+//		return nil
+//	}, "SELECT first_name, last_name FROM users")
+func QueryStructsWith[T any](
+	ctx context.Context,
+	db DBTX,
+	after func(*T) error,
+	query string,
+	args ...any,
+) ([]T, error) {
+	scan := func(s Scanner) (T, error) {
+		t, err := ScanStruct[T](s)
+		if err != nil {
+			return t, err
+		}
+		return t, after(&t)
+	}
+	return queryMany(ctx, db, scan, nil, query, args)
+}
@@ -0,0 +1,87 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type audited struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type Versioned struct {
+	Version int64
+}
+
+func TestSelectAnonymousStruct(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().
+		on("SELECT status, COUNT(*) AS total FROM orders GROUP BY status", []string{"status", "total"},
+			[]driver.Value{"done", int64(2)},
+			[]driver.Value{"open", int64(1)},
+		).
+		on("SELECT id, created_by, version FROM orders", []string{"id", "created_by", "version"},
+			[]driver.Value{int64(1), "ada", int64(1)},
+			[]driver.Value{int64(3), "ada", int64(2)},
+		).
+		on("SELECT id, status FROM orders WHERE id = 2", []string{"id", "status"},
+			[]driver.Value{int64(2), "done"},
+		).
+		on("SELECT id, status FROM orders WHERE id = 42", []string{"id", "status"}).
+		open(t)
+
+	t.Run("flat", func(t *testing.T) {
+		var got []struct {
+			Status string `db:"status"`
+			Total  int64  `db:"total"`
+		}
+		if err := Select(ctx, db, &got, "SELECT status, COUNT(*) AS total FROM orders GROUP BY status"); err != nil {
+			t.Fatal(err)
+		}
+		want := []struct {
+			Status string `db:"status"`
+			Total  int64  `db:"total"`
+		}{{"done", 2}, {"open", 1}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Select = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("embedded", func(t *testing.T) {
+		var got []struct {
+			audited
+			*Versioned
+			ID int64 `db:"id"`
+		}
+		if err := Select(ctx, db, &got, "SELECT id, created_by, version FROM orders"); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Select returned %d rows, want 2", len(got))
+		}
+		for i, want := range []struct{ id, version int64 }{{1, 1}, {3, 2}} {
+			if got[i].ID != want.id || got[i].CreatedBy != "ada" || got[i].Versioned == nil || got[i].Version != want.version {
+				t.Errorf("row %d = %+v, want id %d, created_by ada and version %d", i, got[i], want.id, want.version)
+			}
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var got struct {
+			ID     int64  `db:"id"`
+			Status string `db:"status"`
+		}
+		if err := Get(ctx, db, &got, "SELECT id, status FROM orders WHERE id = 2"); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != 2 || got.Status != "done" {
+			t.Errorf("Get = %+v, want {2 done}", got)
+		}
+		if err := Get(ctx, db, &got, "SELECT id, status FROM orders WHERE id = 42"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get of a missing row = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
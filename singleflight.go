@@ -0,0 +1,96 @@
+package xsql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultFlightTimeout bounds a shared query of a Singleflight.
+const defaultFlightTimeout = 30 * time.Second
+
+// Singleflight coalesces concurrent identical reads on a DBTX into a single
+// round trip, which cuts the database load of cache miss storms.
+// Use it with QueryManyShared.
+type Singleflight struct {
+	db      DBTX
+	g       singleflight.Group
+	timeout atomic.Int64
+}
+
+// NewSingleflight returns a Singleflight reading from db.
+// Shared queries time out after 30 seconds, see SetTimeout.
+func NewSingleflight(db DBTX) *Singleflight {
+	sf := &Singleflight{db: db}
+	sf.SetTimeout(defaultFlightTimeout)
+	return sf
+}
+
+// SetTimeout changes how long a shared query may run.
+// It applies to the queries started afterwards.
+func (sf *Singleflight) SetTimeout(d time.Duration) {
+	sf.timeout.Store(int64(d))
+}
+
+// QueryManyShared is like QueryMany, but concurrent calls on sf with the same
+// row type, query and args share a single query, keyed by a hash of those.
+//
+// The shared query runs detached from the callers' contexts, with the values
+// of the first caller's context and the timeout of sf, so a caller giving up
+// doesn't fail the others: it only stops waiting with its own context error.
+// The query keeps running until it completes or times out, even when every
+// caller stopped waiting. Every caller gets its own copy of the result slice,
+// but the rows themselves are shared, so they must not be mutated when they
+// hold pointers, maps or slices.
+// An error of the query is returned to all the callers sharing it.
+//
+// Calls passing Options are not coalesced, they run their own query, since
+// options like Take or Validate change the result.
+//
+// Example:
+//
+//	sf := NewSingleflight(db)
+//	products, err := QueryManyShared(ctx, sf, scanProduct, "SELECT id, name FROM products WHERE category = ?", cat)
+func QueryManyShared[T any](
+	ctx context.Context,
+	sf *Singleflight,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) ([]T, error) {
+	if _, queryArgs := splitOptions(args); len(queryArgs) != len(args) {
+		return queryMany(ctx, sf.db, scan, nil, query, args)
+	}
+
+	key := flightKey[T](query, args)
+	ch := sf.g.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Duration(sf.timeout.Load()))
+		defer cancel()
+		return QueryMany(ctx, sf.db, scan, query, args...)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		shared := res.Val.([]T)
+		return append(make([]T, 0, len(shared)), shared...), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flightKey returns the key of a shared query. args must not hold Options,
+// they all print the same and would make different calls share a key.
+func flightKey[T any](query string, args []any) string {
+	var zero T
+	h := sha256.New()
+	fmt.Fprintf(h, "%T\x00%s\x00%#v", zero, query, args)
+	return hex.EncodeToString(h.Sum(nil))
+}
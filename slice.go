@@ -0,0 +1,19 @@
+package xsql
+
+// MapSlice returns a new slice holding fn applied to every element of in.
+// It is meant to convert the result of QueryMany to domain types.
+//
+// Example:
+//
+//	rows, err := QueryMany(ctx, db, scanUserRow, "SELECT id, name FROM users")
+//	if err != nil {
+//		return nil, err
+//	}
+//	users := MapSlice(rows, toDomainUser)
+func MapSlice[T, U any](in []T, fn func(T) U) []U {
+	out := make([]U, len(in))
+	for i, v := range in {
+		out[i] = fn(v)
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+// Package sqlite opens SQLite databases configured to work well with package xsql,
+// e.g. as a test backend. It is kept apart from package xsql so only its users
+// depend on the SQLite driver, modernc.org/sqlite.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/freakshake/xsql"
+	"modernc.org/sqlite"
+)
+
+// DefaultPragmas are set by Open on every connection.
+// Foreign keys are off by default in SQLite, and without a busy timeout
+// concurrent access fails immediately with SQLITE_BUSY.
+var DefaultPragmas = []string{
+	"PRAGMA foreign_keys = ON",
+	"PRAGMA busy_timeout = 5000",
+}
+
+// Open opens the SQLite database at dsn, e.g. "file:test.db" or ":memory:",
+// and runs DefaultPragmas followed by pragmas on every new connection,
+// see xsql.NewInitConnector.
+//
+// SQLite allows a single writer at a time, and every connection to ":memory:"
+// gets its own empty database, so the pool is limited to one connection.
+// For file databases with concurrent readers, pass "PRAGMA journal_mode = WAL"
+// and raise the limit with SetMaxOpenConns.
+//
+// Example:
+//
+//	db, err := sqlite.Open("file:test.db", "PRAGMA journal_mode = WAL")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer db.Close()
+func Open(dsn string, pragmas ...string) (*sql.DB, error) {
+	init := append(append([]string(nil), DefaultPragmas...), pragmas...)
+	db := sql.OpenDB(xsql.NewInitConnector(connector{dsn: dsn}, init...))
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+type connector struct {
+	dsn string
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return c.Driver().Open(c.dsn)
+}
+
+func (connector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
@@ -0,0 +1,193 @@
+package xsql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Preparer is a DBTX which can prepare statements.
+// sql.DB, sql.Tx and sql.Conn implement Preparer.
+type Preparer interface {
+	DBTX
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// StmtCacheStats holds the counters of a StmtCache.
+type StmtCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Len is the number of statements currently cached.
+	Len int
+}
+
+// StmtCache is a DBTX which prepares every query once and reuses the prepared
+// statement for later calls with the same query string.
+//
+// The cache holds at most maxEntries statements. When it is full the least
+// recently used statement is evicted and closed, so high cardinality dynamic
+// queries can not leak prepared statements. Evicted statements are closed in
+// a new goroutine once no call is using them anymore.
+//
+// A StmtCache is safe for concurrent use by multiple goroutines.
+//
+// Example:
+//
+//	cache := NewStmtCache(db, 100)
+//	defer cache.Close()
+//	user, err := QueryOne(ctx, cache, scanUser, "SELECT id, name FROM users WHERE id = ?", 1)
+type StmtCache struct {
+	db  Preparer
+	max int
+
+	mu      sync.Mutex
+	lru     *list.List // of *stmtEntry, most recently used first
+	entries map[string]*list.Element
+	stats   StmtCacheStats
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// NewStmtCache returns a StmtCache preparing statements on db
+// and holding at most maxEntries of them. maxEntries < 1 means 1.
+func NewStmtCache(db Preparer, maxEntries int) *StmtCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &StmtCache{
+		db:      db,
+		max:     maxEntries,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// ExecContext executes the cached statement for query.
+func (c *StmtCache) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(e)
+	return e.stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext runs the cached statement for query.
+func (c *StmtCache) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	e, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(e)
+	return e.stmt.QueryContext(ctx, args...)
+}
+
+// QueryRowContext runs the cached statement for query.
+// If preparing fails the query is run unprepared on the wrapped Preparer,
+// since a sql.Row carrying an error can only be built by database/sql.
+func (c *StmtCache) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	e, err := c.acquire(ctx, query)
+	if err != nil {
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	defer c.release(e)
+	return e.stmt.QueryRowContext(ctx, args...)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *StmtCache) Stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Len = c.lru.Len()
+	return s
+}
+
+// Close evicts and closes every cached statement.
+// The cache stays usable and will prepare statements again.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	var toClose []*sql.Stmt
+	for c.lru.Len() > 0 {
+		if stmt := c.evict(c.lru.Back()); stmt != nil {
+			toClose = append(toClose, stmt)
+		}
+	}
+	c.mu.Unlock()
+
+	var err error
+	for _, stmt := range toClose {
+		if cerr := stmt.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (c *StmtCache) acquire(ctx context.Context, query string) (*stmtEntry, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[query]; ok {
+		c.stats.Hits++
+		c.lru.MoveToFront(el)
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		c.mu.Unlock()
+		return e, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Prepare without holding the lock, it is a round trip to the database.
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[query]; ok {
+		// Another goroutine prepared the same query meanwhile.
+		go stmt.Close()
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		return e, nil
+	}
+	e := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.lru.PushFront(e)
+	for c.lru.Len() > c.max {
+		if old := c.evict(c.lru.Back()); old != nil {
+			go old.Close()
+		}
+	}
+	return e, nil
+}
+
+func (c *StmtCache) release(e *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		go e.stmt.Close()
+	}
+}
+
+// evict removes el from the cache. It returns the statement to close,
+// or nil if it is still in use and will be closed by the last release.
+// c.mu must be held.
+func (c *StmtCache) evict(el *list.Element) *sql.Stmt {
+	e := c.lru.Remove(el).(*stmtEntry)
+	delete(c.entries, e.query)
+	c.stats.Evictions++
+	e.evicted = true
+	if e.refs > 0 {
+		return nil
+	}
+	return e.stmt
+}
@@ -0,0 +1,98 @@
+package xsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func newStmtCacheDB() *fakeDB {
+	id := []string{"id"}
+	return newFakeDB().
+		on("SELECT 1", id, []driver.Value{int64(1)}).
+		on("SELECT 2", id, []driver.Value{int64(2)}).
+		on("SELECT 3", id, []driver.Value{int64(3)})
+}
+
+func TestStmtCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	f := newStmtCacheDB()
+	c := NewStmtCache(f.open(t), 2)
+	defer c.Close()
+
+	for _, q := range []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3"} {
+		if _, err := QueryOne(ctx, c, ScanID[int64], q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	want := StmtCacheStats{Hits: 1, Misses: 3, Evictions: 1, Len: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	c.mu.Lock()
+	_, cached1 := c.entries["SELECT 1"]
+	_, cached2 := c.entries["SELECT 2"]
+	c.mu.Unlock()
+	if !cached1 || cached2 {
+		t.Errorf("SELECT 1 cached = %t, SELECT 2 cached = %t, want the least recently used SELECT 2 evicted", cached1, cached2)
+	}
+}
+
+func TestStmtCacheEvictionClosesStmt(t *testing.T) {
+	ctx := context.Background()
+	f := newStmtCacheDB()
+	c := NewStmtCache(f.open(t), 1)
+	defer c.Close()
+
+	for _, q := range []string{"SELECT 1", "SELECT 2"} {
+		if _, err := QueryOne(ctx, c, ScanID[int64], q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	// The evicted statement is closed in a goroutine.
+	deadline := time.Now().Add(time.Second)
+	for f.stmtsClosed("SELECT 1") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("evicted statement was not closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := f.stmtsClosed("SELECT 2"); n != 0 {
+		t.Errorf("cached statement closed %d times", n)
+	}
+}
+
+func TestStmtCacheEvictionWaitsForRelease(t *testing.T) {
+	ctx := context.Background()
+	f := newStmtCacheDB()
+	c := NewStmtCache(f.open(t), 1)
+	defer c.Close()
+
+	e, err := c.acquire(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	stmt := c.evict(c.lru.Back())
+	c.mu.Unlock()
+	if stmt != nil {
+		t.Fatal("evict returned a statement in use")
+	}
+
+	var n int64
+	if err := e.stmt.QueryRowContext(ctx).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("statement in use = %d, %v, want it usable", n, err)
+	}
+
+	c.release(e)
+	deadline := time.Now().Add(time.Second)
+	for f.stmtsClosed("SELECT 1") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("statement was not closed by its last release")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,121 @@
+package xsql
+
+import (
+	"context"
+
+	"github.com/freakshake/xerror"
+)
+
+// Stream runs the query and calls send for every scanned row as soon as it is scanned,
+// without buffering the result. It stops at the first scan or send error and returns it.
+// Rows are always closed.
+//
+// It is meant for streaming responses. When the client disconnects the request
+// context is cancelled, which aborts the query and makes Stream return the context error.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		flusher, _ := w.(http.Flusher)
+//		enc := json.NewEncoder(w)
+//		n := 0
+//		send := func(u User) error {
+//			if err := enc.Encode(u); err != nil {
+//				return err
+//			}
+//			// Flush every 100 rows so the client receives them incrementally.
+//			if n++; n%100 == 0 && flusher != nil {
+//				flusher.Flush()
+//			}
+//			return nil
+//		}
+//		err := Stream(r.Context(), db, scanUser, send, "SELECT id, name FROM users")
+//		if err != nil {
+//			log.Println(err)
+//		}
+//	}
+func Stream[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	send func(T) error,
+	query string,
+	args ...any,
+) (err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		res, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := send(res); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// QueryChan runs the query in a new goroutine and delivers the scanned rows on
+// the returned channel, which is closed once all rows were sent or the query failed.
+// The error channel then receives the terminal error, if any, including the one
+// reported by rows.Err, and is closed too. It is buffered, reading it is optional.
+//
+// Rows are closed and the goroutine stops when ctx is cancelled. The consumer must
+// either drain the row channel or cancel ctx, otherwise the goroutine leaks,
+// blocked on a send, and keeps its connection.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	users, errs := QueryChan(ctx, db, scanUser, 100, "SELECT id, name FROM users")
+//	for u := range users {
+//		process(u)
+//	}
+//	if err := <-errs; err != nil {
+//		panic(err)
+//	}
+func QueryChan[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	bufSize int,
+	query string,
+	args ...any,
+) (<-chan T, <-chan error) {
+	out := make(chan T, bufSize)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(out)
+
+		send := func(v T) error {
+			select {
+			case out <- v:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := Stream(ctx, db, scan, send, query, args...); err != nil {
+			errs <- err
+		}
+	}()
+
+	return out, errs
+}
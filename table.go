@@ -0,0 +1,153 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/freakshake/xerror"
+)
+
+// QueryTable runs the query and returns the result together with its column metadata.
+// It is meant for generic tools, like an admin query console, which don't know
+// the shape of the result up front.
+//
+// Every row is scanned into a []any. Text values the driver returns as []byte
+// are converted to string, binary columns stay []byte and numeric values
+// are kept as int64, uint64 or float64.
+//
+// Cancelling ctx aborts the query and the iteration, the context error is returned.
+//
+// Example:
+//
+//	cols, types, rows, err := QueryTable(ctx, db, "SELECT * FROM users WHERE age > ?", 30)
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, row := range rows {
+//		for i, v := range row {
+//			fmt.Printf("%s (%s) = %v\n", cols[i], types[i].DatabaseTypeName(), v)
+//		}
+//	}
+func QueryTable(
+	ctx context.Context,
+	db DBTX,
+	query string,
+	args ...any,
+) (cols []string, types []*sql.ColumnType, table [][]any, err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	if cols, err = rows.Columns(); err != nil {
+		return nil, nil, nil, err
+	}
+	if types, err = rows.ColumnTypes(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	table = make([][]any, 0, 20)
+
+	for rows.Next() {
+		row := make([]any, len(cols))
+		dest := make([]any, len(cols))
+		for i := range dest {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, nil, err
+		}
+		for i, v := range row {
+			row[i] = normalize(types[i], v)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return cols, types, table, nil
+}
+
+// normalize converts a raw []byte value to a string or a number
+// according to the column type. Other values are returned as is.
+func normalize(ct *sql.ColumnType, v any) any {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+
+	if st := ct.ScanType(); st != nil {
+		s := string(b)
+		switch st.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return n
+			}
+		case reflect.Float32, reflect.Float64:
+			if n, err := strconv.ParseFloat(s, 64); err == nil {
+				return n
+			}
+		}
+	}
+
+	if isBinary(ct.DatabaseTypeName()) {
+		return b
+	}
+	return string(b)
+}
+
+func isBinary(typeName string) bool {
+	t := strings.ToUpper(typeName)
+	return strings.Contains(t, "BLOB") || strings.Contains(t, "BINARY") || t == "BYTEA"
+}
+
+// Describe returns the column metadata of query without fetching its rows.
+//
+// The query is wrapped as SELECT * FROM (query) AS q LIMIT 0, so the database
+// only plans it and returns an empty result set whose columns describe it.
+// Running the query as is and closing the rows would not do: drivers like lib/pq
+// and go-sql-driver/mysql read and discard the remaining rows on Close.
+// The query must therefore be a SELECT, optionally with a WITH clause, which
+// can be used as a subquery. A trailing semicolon is removed, and the query may
+// end with a -- comment. Columns with the same name, as in SELECT * over a join,
+// have to be aliased apart, as MySQL refuses duplicate column names in a subquery.
+//
+// Example:
+//
+//	types, err := Describe(ctx, db, "SELECT id, name FROM users")
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, t := range types {
+//		fmt.Println(t.Name(), t.DatabaseTypeName())
+//	}
+func Describe(ctx context.Context, db DBTX, query string, args ...any) (_ []*sql.ColumnType, err error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	// The newline ends a trailing -- comment before the closing parenthesis.
+	rows, err := db.QueryContext(ctx, "SELECT * FROM ("+query+"\n) AS q LIMIT 0", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	return rows.ColumnTypes()
+}
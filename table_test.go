@@ -0,0 +1,41 @@
+package xsql
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	db := openTestDB(t)
+	mustExec(t, db,
+		"CREATE TABLE users (id INTEGER, name TEXT)",
+		"INSERT INTO users VALUES (1, 'a'), (2, 'b')",
+	)
+
+	tests := []struct {
+		query string
+		args  []any
+		want  []string
+	}{
+		{"SELECT id, name FROM users", nil, []string{"id", "name"}},
+		{"SELECT name AS n FROM users WHERE id = ?;", []any{1}, []string{"n"}},
+		{"WITH u AS (SELECT id FROM users) SELECT id FROM u", nil, []string{"id"}},
+		{"SELECT id FROM users -- all of them", nil, []string{"id"}},
+		{"SELECT id FROM users ;\n", nil, []string{"id"}},
+	}
+	for _, tt := range tests {
+		types, err := Describe(context.Background(), db, tt.query, tt.args...)
+		if err != nil {
+			t.Errorf("Describe(%q): %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, ct := range types {
+			got = append(got, ct.Name())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Describe(%q) columns = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package xsql
+
+import (
+	"context"
+	"time"
+)
+
+// QueryOneTimed is like QueryOne, but also returns the wall-clock duration of the call.
+// It is meant for quick instrumentation, where hooks or metrics would be overkill.
+func QueryOneTimed[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) (T, time.Duration, error) {
+	start := time.Now()
+	res, err := QueryOne(ctx, db, scan, query, args...)
+	return res, time.Since(start), err
+}
+
+// QueryManyTimed is like QueryMany, but also returns the wall-clock duration of the call.
+// The duration includes scanning, which is often where the time goes for large results.
+//
+// Example:
+//
+//	users, took, err := QueryManyTimed(ctx, db, scanUser, "SELECT id, name FROM users")
+//	if err != nil {
+//		panic(err)
+//	}
+//	log.Printf("fetched %d users in %s", len(users), took)
+func QueryManyTimed[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	query string,
+	args ...any,
+) ([]T, time.Duration, error) {
+	start := time.Now()
+	res, err := QueryMany(ctx, db, scan, query, args...)
+	return res, time.Since(start), err
+}
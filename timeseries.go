@@ -0,0 +1,41 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TimePoint is a single bucket of a time series.
+type TimePoint struct {
+	T time.Time
+	V float64
+	// Valid is false for gap buckets whose value is NULL, V is 0 then.
+	Valid bool
+}
+
+// QueryTimeSeries runs a query returning two columns, the bucket timestamp and
+// its value, and returns the points in query order, so ORDER BY the bucket.
+// NULL values are returned as points with Valid set to false.
+//
+// Example:
+//
+//	points, err := QueryTimeSeries(ctx, db, `
+//		SELECT date_trunc('hour', created_at) AS bucket, AVG(latency)
+//		FROM requests
+//		GROUP BY bucket
+//		ORDER BY bucket`)
+func QueryTimeSeries(ctx context.Context, db DBTX, query string, args ...any) ([]TimePoint, error) {
+	return QueryMany(ctx, db, scanTimePoint, query, args...)
+}
+
+func scanTimePoint(s Scanner) (TimePoint, error) {
+	var (
+		t time.Time
+		v sql.NullFloat64
+	)
+	if err := s.Scan(&t, &v); err != nil {
+		return TimePoint{}, err
+	}
+	return TimePoint{T: t, V: v.Float64, Valid: v.Valid}, nil
+}
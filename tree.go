@@ -0,0 +1,85 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCycle is returned by QueryTree when the parent links of the rows form a cycle.
+var ErrCycle = errors.New("xsql: cycle in tree")
+
+// Node is a row of a tree assembled by QueryTree.
+type Node[T any] struct {
+	Value    T
+	Children []*Node[T]
+}
+
+// QueryTree runs the query like QueryMany and assembles the rows into trees,
+// e.g. categories linked by a parent_id column.
+//
+// idOf returns the key of a row, parentOf the key of its parent, or false for
+// a root. Rows whose parent is not part of the result are orphans, they are
+// returned as roots too, so a subtree can be loaded by selecting its rows only.
+// Roots and children keep the order of the rows, so sort them in the query.
+//
+// QueryTree returns ErrCycle when rows are linked in a cycle, as they would
+// never reach a root, and an error when two rows have the same key.
+//
+// Example:
+//
+//	roots, err := QueryTree(ctx, db, scanCategory,
+//		func(c Category) int64 { return c.ID },
+//		func(c Category) (int64, bool) { return c.ParentID.Int64, c.ParentID.Valid },
+//		"SELECT id, parent_id, name FROM categories ORDER BY name",
+//	)
+func QueryTree[K comparable, T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	idOf func(T) K,
+	parentOf func(T) (K, bool),
+	query string,
+	args ...any,
+) ([]*Node[T], error) {
+	values, err := QueryMany(ctx, db, scan, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*Node[T], len(values))
+	index := make(map[K]*Node[T], len(values))
+	for i, v := range values {
+		id := idOf(v)
+		if _, ok := index[id]; ok {
+			return nil, fmt.Errorf("xsql: QueryTree: duplicate key %v", id)
+		}
+		nodes[i] = &Node[T]{Value: v}
+		index[id] = nodes[i]
+	}
+
+	var roots []*Node[T]
+	for i, v := range values {
+		if pid, ok := parentOf(v); ok {
+			if parent, ok := index[pid]; ok {
+				parent.Children = append(parent.Children, nodes[i])
+				continue
+			}
+		}
+		roots = append(roots, nodes[i])
+	}
+
+	// Every node is reachable from a root unless it is part of a cycle.
+	reached := 0
+	stack := append([]*Node[T](nil), roots...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = append(stack[:len(stack)-1], n.Children...)
+		reached++
+	}
+	if reached < len(nodes) {
+		return nil, fmt.Errorf("%w: %d rows don't lead to a root", ErrCycle, len(nodes)-reached)
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,104 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/freakshake/xerror"
+)
+
+// TxOption configures WithTx.
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	warnAfter time.Duration
+	logger    *slog.Logger
+}
+
+// WarnLongTx makes WithTx log a warning with logger when the transaction was open
+// longer than threshold by the time it commits or rolls back. The warning holds
+// the elapsed duration and the caller of WithTx.
+//
+// Long transactions hold locks and keep old row versions alive, this surfaces
+// mistakes like doing HTTP calls inside a transaction. A nil logger means slog.Default().
+func WarnLongTx(threshold time.Duration, logger *slog.Logger) TxOption {
+	return func(c *txConfig) {
+		c.warnAfter = threshold
+		c.logger = logger
+	}
+}
+
+// WithTx runs fn inside a transaction. The transaction is committed if fn returns nil
+// and rolled back if it returns an error or panics.
+//
+// Example:
+//
+//	err := WithTx(ctx, db, func(tx *sql.Tx) error {
+//		if _, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = balance - ? WHERE id = ?", 10, 1); err != nil {
+//			return err
+//		}
+//		_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", 10, 2)
+//		return err
+//	}, WarnLongTx(time.Second, nil))
+func WithTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error, opts ...TxOption) (err error) {
+	var c txConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.warnAfter > 0 {
+		defer warnLongTx(ctx, c, time.Now(), callerOf(2))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			if rerr := tx.Rollback(); rerr != nil {
+				xerror.Wrap(&err, "tx.Rollback(): %s", rerr.Error())
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(tx)
+}
+
+func warnLongTx(ctx context.Context, c txConfig, start time.Time, caller string) {
+	elapsed := time.Since(start)
+	if elapsed <= c.warnAfter {
+		return
+	}
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.WarnContext(ctx, "xsql: long transaction",
+		slog.Duration("elapsed", elapsed),
+		slog.Duration("threshold", c.warnAfter),
+		slog.String("caller", caller),
+	)
+}
+
+// callerOf returns "function file:line" of the caller skip frames up.
+func callerOf(skip int) string {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	name := "unknown"
+	if f := runtime.FuncForPC(pc); f != nil {
+		name = f.Name()
+	}
+	return fmt.Sprintf("%s %s:%d", name, file, line)
+}
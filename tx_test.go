@@ -0,0 +1,104 @@
+package xsql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeDB()
+	db := f.open(t)
+	errFn := errors.New("fn failed")
+
+	insert := func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+		return err
+	}
+	if err := WithTx(ctx, db, insert); err != nil {
+		t.Fatal(err)
+	}
+	if err := WithTx(ctx, db, func(tx *sql.Tx) error {
+		if err := insert(tx); err != nil {
+			return err
+		}
+		return errFn
+	}); !errors.Is(err, errFn) {
+		t.Fatalf("WithTx error = %v, want %v", err, errFn)
+	}
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("recovered %v, want the panic of fn", p)
+			}
+		}()
+		_ = WithTx(ctx, db, func(tx *sql.Tx) error {
+			_ = insert(tx)
+			panic("boom")
+		})
+	}()
+
+	if commits, rollbacks := f.txs(); commits != 1 || rollbacks != 2 {
+		t.Errorf("%d commits and %d rollbacks, want 1 and 2", commits, rollbacks)
+	}
+	if got := f.executed(); len(got) != 3 {
+		t.Errorf("executed %q, want the insert of every transaction", got)
+	}
+}
+
+func TestWithTxWarnLongTx(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB().open(t)
+
+	tests := []struct {
+		name  string
+		sleep time.Duration
+		warn  bool
+	}{
+		{"fast", 0, false},
+		{"slow", 20 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			err := WithTx(ctx, db, func(*sql.Tx) error {
+				time.Sleep(tt.sleep)
+				return nil
+			}, WarnLongTx(10*time.Millisecond, logger))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.warn {
+				if buf.Len() != 0 {
+					t.Errorf("unexpected warning: %s", buf.String())
+				}
+				return
+			}
+			var entry struct {
+				Level   string
+				Msg     string
+				Elapsed int64
+				Caller  string
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("no warning logged: %v", err)
+			}
+			if entry.Level != "WARN" || time.Duration(entry.Elapsed) < tt.sleep {
+				t.Errorf("warning = %+v, want a WARN with elapsed >= %s", entry, tt.sleep)
+			}
+			if !strings.Contains(entry.Caller, "TestWithTxWarnLongTx") || !strings.Contains(entry.Caller, "tx_test.go") {
+				t.Errorf("caller = %q, want the caller of WithTx", entry.Caller)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package xsql
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// QueryArgs is a query along with its arguments.
+type QueryArgs struct {
+	Query string
+	Args  []any
+}
+
+// Concurrent makes QueryManyUnion run up to n of its queries at once,
+// or all of them for n of 0 or less. Each query holds its own connection,
+// so this needs a pool, e.g. a sql.DB, not a sql.Tx or sql.Conn.
+func Concurrent(n int) Option {
+	return optionFunc(func(o *options) {
+		o.concurrency = n
+		if n <= 0 {
+			o.concurrency = -1
+		}
+	})
+}
+
+// QueryManyUnion runs each of queries like QueryMany with the same scan function
+// and returns their rows concatenated in order of queries. It stands in for a
+// UNION ALL which is awkward to write, e.g. over tables with the same projection.
+//
+// The queries run one after the other, or in parallel with the Concurrent option.
+// The other opts apply to each query, e.g. Take(10) takes up to 10 rows per query.
+// QueryManyUnion stops at the first error and returns it, the queries still
+// running are cancelled and their rows closed.
+//
+// Example:
+//
+//	events, err := QueryManyUnion(ctx, db, scanEvent, []QueryArgs{
+//		{Query: "SELECT id, at FROM logins WHERE user_id = ?", Args: []any{id}},
+//		{Query: "SELECT id, at FROM purchases WHERE user_id = ?", Args: []any{id}},
+//	}, Concurrent(0))
+func QueryManyUnion[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	queries []QueryArgs,
+	opts ...Option,
+) ([]T, error) {
+	var o options
+	for _, opt := range opts {
+		opt.apply(&o)
+	}
+
+	results := make([][]T, len(queries))
+	run := func(ctx context.Context, i int) (err error) {
+		args := make([]any, 0, len(queries[i].Args)+len(opts))
+		args = append(args, queries[i].Args...)
+		for _, opt := range opts {
+			args = append(args, opt)
+		}
+		results[i], err = QueryMany(ctx, db, scan, queries[i].Query, args...)
+		return err
+	}
+
+	if o.concurrency == 0 {
+		for i := range queries {
+			if err := run(ctx, i); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(o.concurrency)
+		for i := range queries {
+			g.Go(func() error {
+				return run(gctx, i)
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	n := 0
+	for _, rows := range results {
+		n += len(rows)
+	}
+	union := make([]T, 0, n)
+	for _, rows := range results {
+		union = append(union, rows...)
+	}
+	return union, nil
+}
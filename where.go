@@ -0,0 +1,75 @@
+package xsql
+
+import (
+	"strings"
+)
+
+// Where builds the WHERE clause of a query from optional conditions,
+// keeping the arguments aligned with their placeholders.
+// It is not a query builder, only the WHERE fragment is produced.
+//
+// Example:
+//
+//	clause, args := NewWhere().
+//		And("status = ?", status).
+//		AndIf(minAge > 0, "age >= ?", minAge).
+//		Build()
+//	users, err := QueryMany(ctx, db, scanUser, "SELECT id, name FROM users "+clause, args...)
+type Where struct {
+	conds []string
+	args  []any
+}
+
+// NewWhere returns an empty Where.
+func NewWhere() *Where {
+	return &Where{}
+}
+
+// And adds a condition and its arguments.
+func (w *Where) And(cond string, args ...any) *Where {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+	return w
+}
+
+// AndIf adds a condition and its arguments only if ok is true.
+func (w *Where) AndIf(ok bool, cond string, args ...any) *Where {
+	if !ok {
+		return w
+	}
+	return w.And(cond, args...)
+}
+
+// Build returns the clause, "WHERE " followed by the conditions joined by AND,
+// and the arguments in placeholder order. When there is more than one
+// condition each is parenthesized, so conditions using OR keep their meaning.
+//
+// Without conditions it returns an empty clause and nil args,
+// which can be appended to a query as is.
+func (w *Where) Build() (clause string, args []any) {
+	switch len(w.conds) {
+	case 0:
+		return "", nil
+	case 1:
+		return "WHERE " + w.conds[0], w.args
+	}
+
+	var b strings.Builder
+	b.WriteString("WHERE ")
+	for i, cond := range w.conds {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		b.WriteByte('(')
+		b.WriteString(cond)
+		b.WriteByte(')')
+	}
+	return b.String(), w.args
+}
+
+// Statements implements Builder. It returns the clause of Build,
+// with its placeholders rewritten for dialect by Rebind, as a single Statement.
+func (w *Where) Statements(dialect Dialect) ([]Statement, error) {
+	clause, args := w.Build()
+	return []Statement{{Query: Rebind(dialect, clause), Args: args}}, nil
+}
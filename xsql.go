@@ -7,7 +7,35 @@ import (
 	"github.com/freakshake/xerror"
 )
 
+// DBTX is the common interface of sql.DB, sql.Tx and sql.Conn.
+// Every helper of this package accepts a DBTX, so the same code runs
+// against a pool, inside a transaction or on a dedicated connection.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// RowErrQuerier is implemented by DBTX wrappers which may fail a single row
+// query before it runs, like RateLimited or postgres.CostGuard.
+// A sql.Row can't carry an arbitrary error, so QueryOne and Row call
+// QueryRowErrContext instead of QueryRowContext when db implements it,
+// and return its error as is.
+type RowErrQuerier interface {
+	QueryRowErrContext(ctx context.Context, query string, args ...any) (*sql.Row, error)
+}
+
+// queryRow runs a single row query on db, through QueryRowErrContext when db implements it.
+func queryRow(ctx context.Context, db DBTX, query string, args []any) (*sql.Row, error) {
+	if q, ok := db.(RowErrQuerier); ok {
+		return q.QueryRowErrContext(ctx, query, args...)
+	}
+	return db.QueryRowContext(ctx, query, args...), nil
+}
+
 // QueryOne is used to retrieve a single row from a database using the provided query and arguments.
+// It returns ErrNotFound if the query returned no row.
+// Its behaviour can be changed by passing Options among the arguments.
 //
 // Example:
 //
@@ -34,16 +62,32 @@ import (
 //	}
 func QueryOne[T any](
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	scan func(Scanner) (T, error),
 	query string,
 	args ...any,
-) (_ T, err error) {
-	row := db.QueryRowContext(ctx, query, args...)
-	return scan(row)
+) (res T, err error) {
+	opts, args := splitOptions(args)
+	query = addComment(query, opts.comment)
+	if Debug {
+		if err := ValidateArgs(args); err != nil {
+			return res, err
+		}
+	}
+
+	row, err := queryRow(ctx, db, query, args)
+	if err != nil {
+		return res, mapError(opts, err)
+	}
+	res, err = scan(row)
+	if err != nil {
+		return res, mapError(opts, notFound(err))
+	}
+	return res, nil
 }
 
 // QueryMany is used to retrieve multiple rows from a database using a query and arguments.
+// Its behaviour can be changed by passing Options among the arguments.
 //
 // Example:
 //
@@ -70,11 +114,55 @@ func QueryOne[T any](
 //	}
 func QueryMany[T any](
 	ctx context.Context,
-	db *sql.DB,
+	db DBTX,
 	scan func(Scanner) (_ T, err error),
 	query string,
 	args ...any,
 ) (_ []T, err error) {
+	return queryMany(ctx, db, scan, nil, query, args)
+}
+
+// QueryManyFilter is like QueryMany, but only keeps the rows for which keep returns true.
+// Rejected rows are dropped right after scanning, so they never take room in the result.
+// All rows are still read before rows are closed.
+//
+// Example:
+//
+//	adults, err := QueryManyFilter(ctx, db, scanUser, func(u User) bool {
+//		return u.Age >= 18
+//	}, "SELECT id, name, age FROM users")
+func QueryManyFilter[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	keep func(T) bool,
+	query string,
+	args ...any,
+) ([]T, error) {
+	return queryMany(ctx, db, scan, keep, query, args)
+}
+
+// queryMany implements QueryMany and its variants.
+// A nil keep keeps every row.
+func queryMany[T any](
+	ctx context.Context,
+	db DBTX,
+	scan func(Scanner) (T, error),
+	keep func(T) bool,
+	query string,
+	args []any,
+) (_ []T, err error) {
+	opts, args := splitOptions(args)
+	query = addComment(query, opts.comment)
+	defer func() {
+		err = mapError(opts, err)
+	}()
+	if Debug {
+		if err := ValidateArgs(args); err != nil {
+			return nil, err
+		}
+	}
+
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -86,15 +174,32 @@ func QueryMany[T any](
 		}
 	}()
 
-	results := make([]T, 0, 20)
+	size := 20
+	if opts.take >= 0 && opts.take < size {
+		size = opts.take
+	}
+	results := make([]T, 0, size)
 
-	for rows.Next() {
+	for (opts.take < 0 || len(results) < opts.take) && rows.Next() {
 		res, err := scan(rows)
+		if err == nil && opts.validate != nil {
+			err = opts.validate(res)
+		}
 		if err != nil {
+			if opts.drain {
+				for rows.Next() {
+				}
+			}
 			return nil, err
 		}
+		if keep != nil && !keep(res) {
+			continue
+		}
 		results = append(results, res)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
@@ -0,0 +1,74 @@
+package xsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestQueryManyFilter(t *testing.T) {
+	ctx := context.Background()
+	db := newCountingDB(100).open(t)
+	even := func(n int64) bool { return n%2 == 0 }
+
+	got, err := QueryManyFilter(ctx, db, ScanID[int64], even, "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 50 {
+		t.Fatalf("QueryManyFilter kept %d rows, want 50", len(got))
+	}
+	for _, n := range got {
+		if !even(n) {
+			t.Fatalf("QueryManyFilter kept %d", n)
+		}
+	}
+	// Rejected rows are never appended, so the slice only grew for the kept half.
+	if c := cap(got); c >= 100 {
+		t.Errorf("cap = %d, want room for the kept rows only", c)
+	}
+
+	// Take counts the kept rows.
+	got, err = QueryManyFilter(ctx, db, ScanID[int64], even, "SELECT n", Take(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 4, 6}; !slices.Equal(got, want) {
+		t.Errorf("QueryManyFilter with Take = %v, want %v", got, want)
+	}
+}
+
+var errRefused = errors.New("refused")
+
+// refusingDB is a RowErrQuerier refusing every single row query.
+type refusingDB struct{ DBTX }
+
+func (refusingDB) QueryRowErrContext(context.Context, string, ...any) (*sql.Row, error) {
+	return nil, errRefused
+}
+
+func TestQueryOneRowErrQuerier(t *testing.T) {
+	ctx := context.Background()
+	db := refusingDB{nopDB{}}
+
+	tests := []struct {
+		name string
+		db   DBTX
+	}{
+		{"direct", db},
+		{"hooked", WithHooks(db)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := QueryOne(ctx, tt.db, ScanID[int64], "SELECT 1"); !errors.Is(err, errRefused) {
+				t.Errorf("QueryOne error = %v, want %v", err, errRefused)
+			}
+			var n int64
+			if err := Row(ctx, tt.db, "SELECT 1", nil, &n); !errors.Is(err, errRefused) {
+				t.Errorf("Row error = %v, want %v", err, errRefused)
+			}
+		})
+	}
+}
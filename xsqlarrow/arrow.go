@@ -0,0 +1,314 @@
+// Package xsqlarrow converts query results to Apache Arrow records.
+// It is kept apart from package xsql so only its users depend on Arrow.
+package xsqlarrow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/freakshake/xerror"
+	"github.com/freakshake/xsql"
+)
+
+// QueryArrow runs the query and returns all its rows as a single Arrow record.
+// The caller must Release the record.
+//
+// The Arrow type of each column is inferred from its sql.ColumnType: integers
+// become int64, floating point numbers float64, booleans bool, text utf8,
+// binary data binary and times timestamp[us, UTC]. A column of any other type
+// fails with an error naming it. NULLs are marked in the validity bitmap.
+//
+// Example:
+//
+//	rec, err := xsqlarrow.QueryArrow(ctx, db, "SELECT id, name, created_at FROM users")
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer rec.Release()
+func QueryArrow(ctx context.Context, db xsql.DBTX, query string, args ...any) (arrow.Record, error) {
+	var rec arrow.Record
+	err := StreamArrow(ctx, db, 0, func(r arrow.Record) error {
+		r.Retain()
+		rec = r
+		return nil
+	}, query, args...)
+	if err != nil {
+		if rec != nil {
+			rec.Release()
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// StreamArrow runs the query and calls send with records of up to batchSize rows,
+// without holding the whole result in memory. A batchSize of 0 or less sends all
+// rows in one record. The column types are inferred like QueryArrow does.
+//
+// Every record is released once send returns, so send must Retain a record it
+// keeps. StreamArrow stops at the first send error and returns it. A query
+// without rows sends a single empty record, so the schema is always delivered.
+//
+// Example:
+//
+//	err := xsqlarrow.StreamArrow(ctx, db, 10000, func(rec arrow.Record) error {
+//		return writer.Write(rec)
+//	}, "SELECT id, amount, booked_at FROM payments")
+func StreamArrow(
+	ctx context.Context,
+	db xsql.DBTX,
+	batchSize int,
+	send func(arrow.Record) error,
+	query string,
+	args ...any,
+) (err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	fields := make([]arrow.Field, len(types))
+	for i, ct := range types {
+		dt, err := arrowType(ct)
+		if err != nil {
+			return err
+		}
+		nullable, ok := ct.Nullable()
+		fields[i] = arrow.Field{Name: ct.Name(), Type: dt, Nullable: nullable || !ok}
+	}
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, arrow.NewSchema(fields, nil))
+	defer b.Release()
+
+	flush := func() error {
+		rec := b.NewRecord()
+		defer rec.Release()
+		return send(rec)
+	}
+
+	values := make([]any, len(types))
+	dest := make([]any, len(types))
+	for i := range dest {
+		dest[i] = &values[i]
+	}
+	n, sent := 0, false
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, v := range values {
+			if err := appendValue(b.Field(i), v); err != nil {
+				return fmt.Errorf("xsqlarrow: column %q: %w", fields[i].Name, err)
+			}
+		}
+		if n++; batchSize > 0 && n == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			n, sent = 0, true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if n > 0 || !sent {
+		return flush()
+	}
+	return nil
+}
+
+// arrowType returns the Arrow type for the column ct.
+// The scan type is tried first, then the database type name, since some
+// drivers only report a scan type of any for expressions.
+func arrowType(ct *sql.ColumnType) (arrow.DataType, error) {
+	if st := ct.ScanType(); st != nil {
+		switch st {
+		case reflect.TypeFor[sql.NullInt64](), reflect.TypeFor[sql.NullInt32](),
+			reflect.TypeFor[sql.NullInt16](), reflect.TypeFor[sql.NullByte]():
+			return arrow.PrimitiveTypes.Int64, nil
+		case reflect.TypeFor[sql.NullFloat64]():
+			return arrow.PrimitiveTypes.Float64, nil
+		case reflect.TypeFor[sql.NullBool]():
+			return arrow.FixedWidthTypes.Boolean, nil
+		case reflect.TypeFor[sql.NullString]():
+			return arrow.BinaryTypes.String, nil
+		case reflect.TypeFor[sql.NullTime](), reflect.TypeFor[time.Time]():
+			return timestampType, nil
+		case reflect.TypeFor[sql.RawBytes](), reflect.TypeFor[[]byte]():
+			return arrow.BinaryTypes.Binary, nil
+		}
+		switch st.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return arrow.PrimitiveTypes.Int64, nil
+		case reflect.Float32, reflect.Float64:
+			return arrow.PrimitiveTypes.Float64, nil
+		case reflect.Bool:
+			return arrow.FixedWidthTypes.Boolean, nil
+		case reflect.String:
+			return arrow.BinaryTypes.String, nil
+		}
+	}
+
+	if t, ok := typeByName(ct.DatabaseTypeName()); ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("xsqlarrow: column %q: unsupported type %q", ct.Name(), ct.DatabaseTypeName())
+}
+
+// typeByName returns the Arrow type for the database type name.
+// Names are matched by whole word, so "UNSIGNED BIG INT" is an integer
+// but POINT and INTERVAL are not.
+func typeByName(name string) (arrow.DataType, bool) {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if t, ok := typeNames[w]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// typeNames maps the words of database type names to Arrow types,
+// e.g. "DOUBLE PRECISION" or "VARCHAR(255)".
+var typeNames = map[string]arrow.DataType{
+	"INT": arrow.PrimitiveTypes.Int64, "INTEGER": arrow.PrimitiveTypes.Int64,
+	"TINYINT": arrow.PrimitiveTypes.Int64, "SMALLINT": arrow.PrimitiveTypes.Int64,
+	"MEDIUMINT": arrow.PrimitiveTypes.Int64, "BIGINT": arrow.PrimitiveTypes.Int64,
+	"INT2": arrow.PrimitiveTypes.Int64, "INT4": arrow.PrimitiveTypes.Int64, "INT8": arrow.PrimitiveTypes.Int64,
+	"SMALLSERIAL": arrow.PrimitiveTypes.Int64, "SERIAL": arrow.PrimitiveTypes.Int64, "BIGSERIAL": arrow.PrimitiveTypes.Int64,
+
+	"FLOAT": arrow.PrimitiveTypes.Float64, "FLOAT4": arrow.PrimitiveTypes.Float64, "FLOAT8": arrow.PrimitiveTypes.Float64,
+	"DOUBLE": arrow.PrimitiveTypes.Float64, "REAL": arrow.PrimitiveTypes.Float64,
+
+	"BOOL": arrow.FixedWidthTypes.Boolean, "BOOLEAN": arrow.FixedWidthTypes.Boolean,
+
+	"CHAR": arrow.BinaryTypes.String, "CHARACTER": arrow.BinaryTypes.String,
+	"VARCHAR": arrow.BinaryTypes.String, "NCHAR": arrow.BinaryTypes.String, "NVARCHAR": arrow.BinaryTypes.String,
+	"TEXT": arrow.BinaryTypes.String, "TINYTEXT": arrow.BinaryTypes.String,
+	"MEDIUMTEXT": arrow.BinaryTypes.String, "LONGTEXT": arrow.BinaryTypes.String, "CLOB": arrow.BinaryTypes.String,
+
+	"BLOB": arrow.BinaryTypes.Binary, "TINYBLOB": arrow.BinaryTypes.Binary,
+	"MEDIUMBLOB": arrow.BinaryTypes.Binary, "LONGBLOB": arrow.BinaryTypes.Binary,
+	"BYTEA": arrow.BinaryTypes.Binary, "BINARY": arrow.BinaryTypes.Binary, "VARBINARY": arrow.BinaryTypes.Binary,
+
+	"TIMESTAMP": timestampType, "TIMESTAMPTZ": timestampType, "DATETIME": timestampType, "DATE": timestampType,
+}
+
+var timestampType = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+
+// appendValue appends the database value v to b, or a NULL for a nil v.
+func appendValue(b array.Builder, v any) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		n, err := toInt(v)
+		if err != nil {
+			return err
+		}
+		b.Append(n)
+	case *array.Float64Builder:
+		f, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		b.Append(f)
+	case *array.BooleanBuilder:
+		switch v := v.(type) {
+		case bool:
+			b.Append(v)
+		case int64:
+			b.Append(v != 0)
+		default:
+			return fmt.Errorf("can not convert %T to bool", v)
+		}
+	case *array.StringBuilder:
+		switch v := v.(type) {
+		case string:
+			b.Append(v)
+		case []byte:
+			b.Append(string(v))
+		default:
+			return fmt.Errorf("can not convert %T to string", v)
+		}
+	case *array.BinaryBuilder:
+		switch v := v.(type) {
+		case []byte:
+			b.Append(v)
+		case string:
+			b.AppendString(v)
+		default:
+			return fmt.Errorf("can not convert %T to binary", v)
+		}
+	case *array.TimestampBuilder:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("can not convert %T to timestamp", v)
+		}
+		b.Append(arrow.Timestamp(t.UnixMicro()))
+	default:
+		return fmt.Errorf("unsupported builder %T", b)
+	}
+	return nil
+}
+
+func toInt(v any) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	// go-sql-driver/mysql returns unsigned BIGINT as uint64, which is mapped
+	// to Int64 as Arrow has no column type for the values above math.MaxInt64.
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("uint64 value %d overflows int64", v)
+		}
+		return int64(v), nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("can not convert %T to int64", v)
+}
+
+func toFloat(v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case []byte:
+		return strconv.ParseFloat(string(v), 64)
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("can not convert %T to float64", v)
+}
@@ -0,0 +1,69 @@
+package xsqlarrow
+
+import (
+	"math"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+func TestTypeByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want arrow.DataType
+	}{
+		{"INT", arrow.PrimitiveTypes.Int64},
+		{"integer", arrow.PrimitiveTypes.Int64},
+		{"BIGINT", arrow.PrimitiveTypes.Int64},
+		{"INT8", arrow.PrimitiveTypes.Int64},
+		{"UNSIGNED BIG INT", arrow.PrimitiveTypes.Int64},
+		{"INT UNSIGNED", arrow.PrimitiveTypes.Int64},
+		{"BIGSERIAL", arrow.PrimitiveTypes.Int64},
+		{"DOUBLE PRECISION", arrow.PrimitiveTypes.Float64},
+		{"FLOAT8", arrow.PrimitiveTypes.Float64},
+		{"BOOLEAN", arrow.FixedWidthTypes.Boolean},
+		{"VARCHAR(255)", arrow.BinaryTypes.String},
+		{"CHARACTER VARYING", arrow.BinaryTypes.String},
+		{"LONGTEXT", arrow.BinaryTypes.String},
+		{"BYTEA", arrow.BinaryTypes.Binary},
+		{"VARBINARY(16)", arrow.BinaryTypes.Binary},
+		{"TIMESTAMP WITH TIME ZONE", timestampType},
+		{"DATETIME", timestampType},
+		{"POINT", nil},
+		{"INTERVAL", nil},
+		{"TIME", nil},
+		{"JSONB", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, ok := typeByName(tt.name)
+		if ok != (tt.want != nil) || (ok && !arrow.TypeEqual(got, tt.want)) {
+			t.Errorf("typeByName(%q) = %v, %t, want %v", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestToInt(t *testing.T) {
+	tests := []struct {
+		v    any
+		want int64
+		ok   bool
+	}{
+		{int64(-1), -1, true},
+		{uint64(math.MaxInt64), math.MaxInt64, true},
+		{uint64(math.MaxInt64 + 1), 0, false},
+		{[]byte("42"), 42, true},
+		{"42", 42, true},
+		{1.5, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := toInt(tt.v)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("toInt(%v) = %d, %v, want %d and ok %t", tt.v, got, err, tt.want, tt.ok)
+		}
+	}
+
+	if f, err := toFloat(uint64(math.MaxUint64)); err != nil || f != math.MaxUint64 {
+		t.Errorf("toFloat(MaxUint64) = %g, %v", f, err)
+	}
+}
@@ -0,0 +1,4 @@
+// Package testpb holds the protobuf messages used by the tests of xsqlproto.
+package testpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative user.proto
@@ -0,0 +1,224 @@
+// Package xsqlproto scans query results into protobuf messages.
+// It is kept apart from package xsql so only its users depend on protobuf.
+package xsqlproto
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/freakshake/xsql"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var timestampName = (&timestamppb.Timestamp{}).ProtoReflect().Descriptor().FullName()
+
+// ScanProto scans the current row into a new message of type T.
+//
+// Columns are matched to fields by their proto name, which is snake_case by
+// convention just like column names. Every column must have a matching field.
+// NULL values leave the field unset. google.protobuf.Timestamp fields are
+// filled from time values, enum fields from their number or value name.
+// Repeated, map and other message fields are not supported.
+//
+// ScanProto needs a xsql.ColumnScanner, so it is meant to be used with xsql.QueryMany.
+//
+// Example:
+//
+//	users, err := xsql.QueryMany(ctx, db, xsqlproto.ScanProto[*pb.User], "SELECT id, name, created_at FROM users")
+func ScanProto[T proto.Message](s xsql.Scanner) (T, error) {
+	var zero T
+	cs, ok := s.(xsql.ColumnScanner)
+	if !ok {
+		return zero, xsql.ErrNoColumns
+	}
+	cols, err := cs.Columns()
+	if err != nil {
+		return zero, err
+	}
+
+	msg := zero.ProtoReflect().New()
+	fields := msg.Descriptor().Fields()
+
+	fds := make([]protoreflect.FieldDescriptor, len(cols))
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i, col := range cols {
+		fd := fields.ByName(protoreflect.Name(col))
+		if fd == nil {
+			return zero, fmt.Errorf("xsqlproto: missing field for column %q in %s", col, msg.Descriptor().FullName())
+		}
+		fds[i] = fd
+		dest[i] = &values[i]
+	}
+	if err := s.Scan(dest...); err != nil {
+		return zero, err
+	}
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		pv, err := protoValue(fds[i], v)
+		if err != nil {
+			return zero, fmt.Errorf("xsqlproto: column %q: %w", cols[i], err)
+		}
+		msg.Set(fds[i], pv)
+	}
+
+	return msg.Interface().(T), nil
+}
+
+// protoValue converts the database value v to a value of the field fd.
+func protoValue(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, error) {
+	if fd.IsList() || fd.IsMap() {
+		return protoreflect.Value{}, fmt.Errorf("unsupported field %s", fd.FullName())
+	}
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := toBool(v)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := toInt(v, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := toInt(v, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := toUint(v, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := toUint(v, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := toFloat(v, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := toFloat(v, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		s, err := toString(v)
+		return protoreflect.ValueOfString(s), err
+	case protoreflect.BytesKind:
+		b, err := toBytes(v)
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.EnumKind:
+		return enumValue(fd, v)
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() != timestampName {
+			return protoreflect.Value{}, fmt.Errorf("unsupported message field %s", fd.FullName())
+		}
+		t, err := toTime(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(timestamppb.New(t).ProtoReflect()), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported field %s", fd.FullName())
+}
+
+func enumValue(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, error) {
+	if s, err := toString(v); err == nil {
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+	}
+	n, err := toInt(v, 32)
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf("invalid %s value %v", fd.Enum().FullName(), v)
+	}
+	return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+}
+
+func toBool(v any) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case []byte:
+		return strconv.ParseBool(string(v))
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, fmt.Errorf("can not convert %T to bool", v)
+}
+
+func toInt(v any, bits int) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		if bits == 32 && int64(int32(v)) != v {
+			return 0, fmt.Errorf("value %d overflows int32", v)
+		}
+		return v, nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, bits)
+	case string:
+		return strconv.ParseInt(v, 10, bits)
+	}
+	return 0, fmt.Errorf("can not convert %T to int%d", v, bits)
+}
+
+func toUint(v any, bits int) (uint64, error) {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 || bits == 32 && uint64(uint32(v)) != uint64(v) {
+			return 0, fmt.Errorf("value %d overflows uint%d", v, bits)
+		}
+		return uint64(v), nil
+	case []byte:
+		return strconv.ParseUint(string(v), 10, bits)
+	case string:
+		return strconv.ParseUint(v, 10, bits)
+	}
+	return 0, fmt.Errorf("can not convert %T to uint%d", v, bits)
+}
+
+func toFloat(v any, bits int) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case []byte:
+		return strconv.ParseFloat(string(v), bits)
+	case string:
+		return strconv.ParseFloat(v, bits)
+	}
+	return 0, fmt.Errorf("can not convert %T to float%d", v, bits)
+}
+
+func toString(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("can not convert %T to string", v)
+}
+
+func toBytes(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte(nil), v...), nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("can not convert %T to bytes", v)
+}
+
+func toTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case []byte:
+		return time.Parse(time.RFC3339Nano, string(v))
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	}
+	return time.Time{}, fmt.Errorf("can not convert %T to time", v)
+}
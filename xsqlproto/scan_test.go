@@ -0,0 +1,124 @@
+package xsqlproto
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/freakshake/xsql"
+	"github.com/freakshake/xsql/xsqlproto/internal/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeRow is a xsql.ColumnScanner holding a single row, as returned by a driver.
+type fakeRow struct {
+	cols []string
+	vals []any
+}
+
+// row returns the fakeRow of the column name and value pairs kv.
+func row(kv ...any) *fakeRow {
+	r := &fakeRow{}
+	for i := 0; i < len(kv); i += 2 {
+		r.cols = append(r.cols, kv[i].(string))
+		r.vals = append(r.vals, kv[i+1])
+	}
+	return r
+}
+
+func (r *fakeRow) Columns() ([]string, error) { return r.cols, nil }
+
+func (r *fakeRow) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if len(dest) != len(r.vals) {
+		return fmt.Errorf("fake: %d destinations for %d columns", len(dest), len(r.vals))
+	}
+	for i, d := range dest {
+		*d.(*any) = r.vals[i]
+	}
+	return nil
+}
+
+func TestScanProto(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		row  *fakeRow
+		want *testpb.User
+	}{
+		{
+			name: "all kinds",
+			row: row("id", int64(1), "name", "ada", "active", true, "age", int64(36), "visits", int64(7),
+				"score", 9.5, "avatar", []byte{1, 2}, "role", int64(1), "created_at", created),
+			want: &testpb.User{
+				Id: 1, Name: "ada", Active: true, Age: 36, Visits: 7, Score: 9.5,
+				Avatar: []byte{1, 2}, Role: testpb.Role_ROLE_ADMIN, CreatedAt: timestamppb.New(created),
+			},
+		},
+		{
+			name: "enum by name",
+			row:  row("role", []byte("ROLE_MEMBER")),
+			want: &testpb.User{Role: testpb.Role_ROLE_MEMBER},
+		},
+		{
+			name: "nulls leave fields unset",
+			row:  row("id", int64(2), "name", nil, "created_at", nil),
+			want: &testpb.User{Id: 2},
+		},
+		{
+			name: "text values",
+			row: row("id", []byte("3"), "active", "true", "score", []byte("1.25"),
+				"created_at", created.Format(time.RFC3339Nano)),
+			want: &testpb.User{Id: 3, Active: true, Score: 1.25, CreatedAt: timestamppb.New(created)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScanProto[*testpb.User](tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("ScanProto = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanProtoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		row  *fakeRow
+	}{
+		{"unknown column", row("email", "ada@example.com")},
+		{"int32 overflow", row("age", int64(3000000000))},
+		{"negative uint32", row("visits", int64(-1))},
+		{"bad enum", row("role", "ROLE_OWNER")},
+		{"bad time", row("created_at", "yesterday")},
+		{"repeated field", row("tags", "a")},
+		{"wrong type", row("name", int64(1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ScanProto[*testpb.User](tt.row); err == nil {
+				t.Errorf("ScanProto = %v, want an error", got)
+			}
+		})
+	}
+}
+
+// scannerFunc is a xsql.Scanner which does not expose its columns, like sql.Row.
+type scannerFunc func(dest ...any) error
+
+func (f scannerFunc) Scan(dest ...any) error { return f(dest...) }
+
+func TestScanProtoNoColumns(t *testing.T) {
+	s := scannerFunc(func(...any) error { return nil })
+	if _, err := ScanProto[*testpb.User](s); !errors.Is(err, xsql.ErrNoColumns) {
+		t.Errorf("ScanProto error = %v, want %v", err, xsql.ErrNoColumns)
+	}
+}
@@ -0,0 +1,2 @@
+// Package xsqltest provides test assertions for code using package xsql.
+package xsqltest
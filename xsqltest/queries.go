@@ -0,0 +1,63 @@
+package xsqltest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/freakshake/xsql"
+)
+
+// AssertOption configures AssertMaxQueries.
+type AssertOption func(*assertConfig)
+
+type assertConfig struct {
+	excludeTx bool
+}
+
+// ExcludeTx makes AssertMaxQueries ignore transaction control statements
+// (BEGIN, START TRANSACTION, COMMIT, ROLLBACK, SAVEPOINT and RELEASE).
+func ExcludeTx() AssertOption {
+	return func(c *assertConfig) {
+		c.excludeTx = true
+	}
+}
+
+// AssertMaxQueries fails the test if rec recorded more than k statements.
+// It guards against N+1 regressions, where a code path accidentally issues a query per row.
+// All statements recorded since rec was created or Reset are counted.
+//
+// Example:
+//
+//	rec := xsql.NewRecorder(db)
+//	defer xsqltest.AssertMaxQueries(t, rec, 2, xsqltest.ExcludeTx())
+//	handler(rec, req)
+func AssertMaxQueries(t testing.TB, rec *xsql.Recorder, k int, opts ...AssertOption) {
+	t.Helper()
+
+	var c assertConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var queries []string
+	for _, r := range rec.Recorded() {
+		if c.excludeTx && isTxStatement(r.Query) {
+			continue
+		}
+		queries = append(queries, r.Query)
+	}
+
+	if len(queries) > k {
+		t.Errorf("xsqltest: %d queries issued, want at most %d:\n\t%s", len(queries), k, strings.Join(queries, "\n\t"))
+	}
+}
+
+func isTxStatement(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	for _, prefix := range []string{"BEGIN", "START TRANSACTION", "COMMIT", "ROLLBACK", "SAVEPOINT", "RELEASE"} {
+		if strings.HasPrefix(q, prefix) {
+			return true
+		}
+	}
+	return false
+}
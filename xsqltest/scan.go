@@ -0,0 +1,79 @@
+package xsqltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/freakshake/xerror"
+	"github.com/freakshake/xsql"
+)
+
+// AssertScanMatchesColumns runs query once on db and fails the test unless scan
+// consumes exactly as many columns as the query returns. It catches a column added
+// to the SELECT without a destination in the scan function, or the other way round.
+//
+// The query must return at least one row, as scan is called on the first one.
+// The check relies on scan calling Scan once with all its destinations, as
+// scan functions written for QueryOne and QueryMany do.
+//
+// Example:
+//
+//	xsqltest.AssertScanMatchesColumns(t, db, scanUser, "SELECT id, name, email FROM users LIMIT 1")
+func AssertScanMatchesColumns[T any](
+	t testing.TB,
+	db xsql.DBTX,
+	scan func(xsql.Scanner) (T, error),
+	query string,
+	args ...any,
+) {
+	t.Helper()
+
+	if err := assertScanMatchesColumns(t, db, scan, query, args); err != nil {
+		t.Errorf("xsqltest: %s", err)
+	}
+}
+
+func assertScanMatchesColumns[T any](
+	t testing.TB,
+	db xsql.DBTX,
+	scan func(xsql.Scanner) (T, error),
+	query string,
+	args []any,
+) (err error) {
+	t.Helper()
+
+	rows, err := db.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := rows.Close()
+		if cerr != nil {
+			xerror.Wrap(&err, "rows.Close(): %s", cerr.Error())
+		}
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		t.Errorf("xsqltest: query returned no rows, can't check the scan function: %s", query)
+		return nil
+	}
+
+	s := &xsql.CountingScanner{Scanner: rows}
+	_, scanErr := scan(s)
+	switch {
+	case s.Calls == 0:
+		t.Errorf("xsqltest: scan function never called Scan: %s", query)
+	case s.Dests != len(cols):
+		t.Errorf("xsqltest: scan function has %d destinations for %d columns %q: %s", s.Dests, len(cols), cols, query)
+	case scanErr != nil:
+		t.Errorf("xsqltest: scan function failed: %s", scanErr)
+	}
+	return nil
+}
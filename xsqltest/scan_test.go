@@ -0,0 +1,67 @@
+package xsqltest
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/freakshake/xsql"
+	_ "modernc.org/sqlite"
+)
+
+// recordingTB is a testing.TB recording its errors instead of failing.
+type recordingTB struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestAssertScanMatchesColumns(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	scanID := func(s xsql.Scanner) (id int64, err error) {
+		return id, s.Scan(&id)
+	}
+
+	tests := []struct {
+		name    string
+		assert  func(testing.TB)
+		wantErr string
+	}{
+		{"ScanStruct", func(tb testing.TB) {
+			AssertScanMatchesColumns(tb, db, xsql.ScanStruct[user], "SELECT 1 AS id, 'ada' AS name")
+		}, ""},
+		{"ScanMap", func(tb testing.TB) {
+			AssertScanMatchesColumns(tb, db, xsql.ScanMap, "SELECT 1 AS id, 'ada' AS name")
+		}, ""},
+		{"missing destination", func(tb testing.TB) {
+			AssertScanMatchesColumns(tb, db, scanID, "SELECT 1 AS id, 'ada' AS name")
+		}, "1 destinations for 2 columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingTB{TB: t}
+			tt.assert(rec)
+			switch {
+			case tt.wantErr == "" && len(rec.errs) > 0:
+				t.Errorf("AssertScanMatchesColumns failed: %q", rec.errs)
+			case tt.wantErr != "" && (len(rec.errs) != 1 || !strings.Contains(rec.errs[0], tt.wantErr)):
+				t.Errorf("AssertScanMatchesColumns errors = %q, want one holding %q", rec.errs, tt.wantErr)
+			}
+		})
+	}
+}